@@ -0,0 +1,89 @@
+package urlparser
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// buildOrder is the order in which Build sets the components, so that the specific ones (port)
+// override the general ones (authority). Other components are set afterwards, sorted by name.
+var buildOrder = []string{
+	Scheme, Opaque, Authority, Auth, User, Password, Hosts, HostPort, Host, Tld, Port,
+	RelativeUrl, Path, RawPath, BasePath, File, Ext, Database, Query, Fragment,
+}
+
+// URLBuilder assembles URLs from component values. The values are expected in the same encoding
+// as the components are returned in by Component, and are set in the order they were given.
+type URLBuilder struct {
+	// Parser is used for the components which depend on its options, e.g. TrailingSlashDirs for file.
+	Parser Parser
+
+	components [][2]string
+}
+
+func (b *URLBuilder) Set(component string, value string) *URLBuilder {
+	b.components = append(b.components, [2]string{component, value})
+
+	return b
+}
+
+// Build returns the assembled URL, checking that it can be parsed again.
+func (b *URLBuilder) Build() (string, error) {
+	parsedUrl := &urlParts{URL: &url.URL{}}
+	for _, component := range b.components {
+		err := b.Parser.setComponent(parsedUrl, component[0], component[1])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(parsedUrl.hosts) > 1 {
+		parsedUrl.Host = strings.Join(parsedUrl.hosts, ",")
+	}
+
+	result := parsedUrl.String()
+
+	_, err := parseUrl(result)
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+// Build assembles the URL from the component values, see URLBuilder. Parsing a URL into scheme, opaque,
+// authority and relativeUrl and building it back gives the same URL, as url.URL.String would print it.
+func Build(components map[string]string) (string, error) {
+	builder := &URLBuilder{}
+	for _, component := range buildOrder {
+		if value, ok := components[component]; ok {
+			builder.Set(component, value)
+		}
+	}
+
+	var others []string
+	for component := range components {
+		if !isBuildOrdered(component) {
+			others = append(others, component)
+		}
+	}
+
+	sort.Strings(others)
+
+	for _, component := range others {
+		builder.Set(component, components[component])
+	}
+
+	return builder.Build()
+}
+
+func isBuildOrdered(component string) bool {
+	for _, ordered := range buildOrder {
+		if component == ordered {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,9 +2,17 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/grongor/go-url-parser"
 )
@@ -15,27 +23,164 @@ func usage() {
 	fmt.Println("Usage:")
 	fmt.Printf("    %s <option> [url]...\n\n", app)
 	fmt.Println("Options:")
-	fmt.Printf("    -c, --component=COMPONENT\n\n")
-	fmt.Printf("        Prints single URL component from each. URL.\n\n")
+	fmt.Printf("    -c, --component=COMPONENT[,COMPONENT]...\n\n")
+	fmt.Println("        Prints single URL component from each. URL. May be given multiple times")
+	fmt.Println("        or as a comma-separated list to print several components in columns")
+	fmt.Println("        separated by --delimiter. The list is split only if all its parts are")
+	fmt.Printf("        valid components, so that query:a,b prints the parameter \"a,b\".\n\n")
 	fmt.Println("        Valid values: scheme, authority, auth, user, password,")
 	fmt.Println("                      hostport, host, tld, port, path, query,")
-	fmt.Printf("                      fragment, basePath, file, ext, relativeUrl\n\n")
+	fmt.Println("                      fragment, basePath, file, ext, relativeUrl,")
+	fmt.Println("                      effectivePort, hostPortEffective, hosts,")
+	fmt.Println("                      database, options, hostType, ipVersion,")
+	fmt.Println("                      isLoopback, isPrivate, isLinkLocal, isMulticast,")
+	fmt.Println("                      ipv4, isObfuscatedIpv4, rawPath, segmentCount,")
+	fmt.Println("                      plainPath, matrix, lastExt, fileStem, mimeType,")
+	fmt.Println("                      dirname, basename, hasTrailingSlash, rawUser,")
+	fmt.Println("                      rawPassword, rawAuth, opaque, mailtoRecipients,")
+	fmt.Println("                      mailtoHeaders, urnNamespace, urnNss, dataMediaType,")
+	fmt.Printf("                      dataCharset, dataBase64, dataSize\n\n")
 	fmt.Printf("        Additionally, some components may be further formatted:\n")
 	fmt.Println("            host:x, host:x:y            print only desired (sub)domains")
 	fmt.Println("            hostport:x, hostport:x:y    same as host:*, but also includes port")
-	fmt.Println("            path:x, path:x:y            print only desired parts of the path")
+	fmt.Println("            path:x, path:x:y            print only desired parts of the path (escaped)")
+	fmt.Println("            segment:x                   print single decoded segment of the path")
+	fmt.Println("            matrix:x:NAME               print matrix parameter NAME of path segment x")
 	fmt.Println("            query:NAME                  print only query parameter named NAME")
 	fmt.Println("            fragment:NAME               print only fragment part named NAME")
 	fmt.Println("")
 	fmt.Println("                x    starting position; use -x to start from end")
 	fmt.Println("                y    count; how many parts of the component you want to print")
 	fmt.Println("")
+	fmt.Println("        With -x, the last x parts are skipped and the y parts before them are")
+	fmt.Println("        printed, so path:-1:2 of /a/b/c/d is /b/c.")
+	fmt.Println("")
+	fmt.Println("        effectivePort falls back to the default port of the scheme")
+	fmt.Println("        (http 80, https 443, postgres 5432, ...) when the URL has none.")
+	fmt.Println("")
+	fmt.Println("        hosts lists all hosts of multi-host connection strings (mongodb://h1,h2/db,")
+	fmt.Println("        postgres, redis, ...; other schemes keep commas in the host), database")
+	fmt.Println("        is the first path segment of connection strings and options are their")
+	fmt.Println("        query parameters, sorted by name.")
+	fmt.Println("")
+	fmt.Println("        hostType is one of dns, ipv4, ipv6, ipv6-zone or empty; the is*")
+	fmt.Println("        components print true or false depending on the IP address host.")
+	fmt.Println("        IPv4 hosts are recognised the way browsers do, including decimal")
+	fmt.Println("        (2130706433), octal (0177.0.0.1), hex (0x7f.1) and shortened forms;")
+	fmt.Println("        ipv4 prints them as a dotted quad and isObfuscatedIpv4 flags them.")
+	fmt.Println("")
+	fmt.Println("        Matrix parameters (/cars;color=red/index.jsp;jsessionid=1) are left out")
+	fmt.Println("        of file, ext, path:x:y and segment:x; plainPath prints the path without")
+	fmt.Println("        them and matrix lists them all.")
+	fmt.Println("")
+	fmt.Println("        ext is the part of file after the last dot, or a known compound")
+	fmt.Println("        extension like tar.gz; lastExt is always the part after the last dot")
+	fmt.Println("        and fileStem is the file name without ext.")
+	fmt.Println("")
+	fmt.Println("        dirname and basename split the path like their POSIX counterparts.")
+	fmt.Println("")
+	fmt.Println("        opaque is the part after the scheme of URLs without slashes,")
+	fmt.Println("        like mailto:user@example.com, tel:+1-201-555-0123 or urn:isbn:0451450523.")
+	fmt.Println("        mailtoRecipients lists the addresses of mailto URLs, including the")
+	fmt.Println("        \"to\" headers; mailtoHeaders are the other headers (use query:NAME")
+	fmt.Println("        for a single one). urnNamespace and urnNss split URNs. For data URLs,")
+	fmt.Println("        dataMediaType and dataCharset default to text/plain;charset=US-ASCII,")
+	fmt.Println("        dataBase64 prints true or false and dataSize the decoded length in bytes.")
+	fmt.Println("")
+	fmt.Println("        Components holding a single value (user, password, host, path, fragment,")
+	fmt.Println("        query:NAME, segment:x, file, ...) are percent-decoded. Components made")
+	fmt.Println("        of several parts (authority, auth, query, relativeUrl, rawPath, path:x:y)")
+	fmt.Println("        are percent-encoded, so that they can be parsed again. rawUser,")
+	fmt.Println("        rawPassword and rawAuth print the user info exactly as it was given.")
+	fmt.Println("")
+	fmt.Printf("    -d, --delimiter=DELIMITER\n\n")
+	fmt.Printf("        Separates the columns of multiple components. Defaults to TAB.\n\n")
+	fmt.Printf("    --kv\n\n")
+	fmt.Println("        Prints the components as COMPONENT=VALUE pairs separated by spaces")
+	fmt.Println("        (logfmt), instead of the columns. Components and values containing")
+	fmt.Printf("        spaces, quotes, equal signs or control characters are quoted.\n\n")
 	fmt.Printf("    -f, --format=FORMAT\n\n")
 	fmt.Printf("        Prints URLs formatted according to FORMAT.\n\n")
 	fmt.Println("        FORMAT is an arbitrary string containing component placeholders")
 	fmt.Println("        enclosed in curly brackets. Each {COMPONENT} will be replaced")
 	fmt.Println("        as if url-parser was called with option -c COMPONENT")
 	fmt.Println("")
+	fmt.Printf("    --data\n\n")
+	fmt.Println("        Decodes data: URLs (base64 or percent-encoded) and prints the media type")
+	fmt.Println("        with its parameters, whether the payload is base64 and its length in bytes,")
+	fmt.Printf("        separated by --delimiter.\n\n")
+	fmt.Printf("    --payload=FILE\n\n")
+	fmt.Println("        Decodes data: URLs and writes their payloads to FILE, or to the standard")
+	fmt.Printf("        output if FILE is -. Payloads of multiple URLs are concatenated.\n\n")
+	fmt.Printf("    --whatwg\n\n")
+	fmt.Println("        Parses URLs according to the WHATWG URL Standard, the way browsers do,")
+	fmt.Println("        instead of RFC 3986. Backslashes, tabs and newlines, numeric IPv4 hosts")
+	fmt.Printf("        and internationalized domains are handled as a browser would.\n\n")
+	fmt.Printf("    --lenient\n\n")
+	fmt.Println("        Accepts sloppy input: trims surrounding whitespace, quotes and angle")
+	fmt.Println("        brackets, fixes slashes after the scheme (http:/example.com) and")
+	fmt.Printf("        recognizes scheme-less URLs (example.com/path, //cdn.example.com).\n\n")
+	fmt.Printf("    --assume-scheme=SCHEME\n\n")
+	fmt.Printf("        Same as --lenient, but also adds SCHEME to scheme-less URLs.\n\n")
+	fmt.Printf("    --raw\n\n")
+	fmt.Println("        Prints components exactly as they appeared in the URL. Single component")
+	fmt.Printf("        may be printed raw by adding !raw suffix to it, e.g. path!raw.\n\n")
+	fmt.Printf("    --decode\n\n")
+	fmt.Println("        Prints all components percent-decoded. Single component may be printed")
+	fmt.Printf("        decoded by adding !decoded suffix to it, e.g. query!decoded.\n\n")
+	fmt.Printf("    --trailing-slash-dirs\n\n")
+	fmt.Println("        Treats only paths with a trailing slash as directories, so that file")
+	fmt.Printf("        prints the last part of the path even if it has no extension.\n\n")
+	fmt.Printf("    --trailing-slash=add|remove\n\n")
+	fmt.Printf("        Adds or removes the trailing slash of each path before it's processed.\n\n")
+	fmt.Printf("    --redact\n\n")
+	fmt.Println("        Masks the password and sensitive query parameters of each URL, as well")
+	fmt.Println("        as those in fragments (#access_token=...), before it's processed.")
+	fmt.Println("        When used without --component or --format,")
+	fmt.Printf("        the redacted URLs are printed. The rest of the URL is kept intact.\n\n")
+	fmt.Printf("    --redact-user\n\n")
+	fmt.Printf("        Same as --redact, but masks the whole user info.\n\n")
+	fmt.Printf("    --redact-params=PATTERNS\n\n")
+	fmt.Println("        Comma-separated glob patterns of query and fragment parameter names to mask.")
+	fmt.Printf("        Defaults to %s\n\n", strings.Join(urlparser.RedactedParams, ","))
+	fmt.Printf("    --rules=FILE\n\n")
+	fmt.Println("        Rewrites each URL according to the rules in the YAML FILE before it's")
+	fmt.Println("        processed. Rules are applied in order; each one that matches (all its")
+	fmt.Println("        conditions hold) runs its actions, and \"last: true\" stops the rest.")
+	fmt.Println("        Values of set may contain {COMPONENT} placeholders as in --format.")
+	fmt.Println("        When used without --component or --format, the rewritten URLs are printed.")
+	fmt.Println("")
+	fmt.Println("            rules:")
+	fmt.Println("              - name: blog migration")
+	fmt.Println("                match:                     # equals, prefix, suffix or regex; not: true negates")
+	fmt.Println("                  - component: host")
+	fmt.Println("                    equals: blog.example.com")
+	fmt.Println("                actions:                   # set, remove or replace")
+	fmt.Println("                  - set: host")
+	fmt.Println("                    value: www.example.com")
+	fmt.Println("                  - replace: path")
+	fmt.Println("                    regex: ^/(\\d{4})/(.*)$")
+	fmt.Println("                    with: /blog/$1/$2")
+	fmt.Println("                  - remove: query:utm_source")
+	fmt.Printf("                last: true\n\n")
+	fmt.Printf("    -s, --set=COMPONENT=VALUE\n\n")
+	fmt.Println("        Replaces COMPONENT of each URL with VALUE before it's processed. May be")
+	fmt.Println("        given multiple times; the replacements are applied in order. Supports")
+	fmt.Println("        the same components as --component, except the computed ones (hostType,")
+	fmt.Println("        segmentCount, ...). Empty VALUE removes the component. When used without")
+	fmt.Printf("        --component or --format, the rewritten URLs are printed.\n\n")
+	fmt.Printf("    --workers=N\n\n")
+	fmt.Println("        Processes the URLs with N parallel workers; 0 uses one worker per CPU.")
+	fmt.Println("        The results are still printed in the order of the input. Defaults to 1.")
+	fmt.Println("")
+	fmt.Printf("    --unordered\n\n")
+	fmt.Println("        Prints the results of --workers as soon as they are ready, regardless")
+	fmt.Printf("        of the order of the input, for better throughput.\n\n")
+	fmt.Printf("    -0, --null\n\n")
+	fmt.Println("        URLs read from the standard input are delimited by NUL instead of newline,")
+	fmt.Printf("        e.g. for input produced by find -print0.\n\n")
+	fmt.Printf("    -z, --zero-terminated\n\n")
+	fmt.Printf("        Delimits the results by NUL instead of newline.\n\n")
 	fmt.Printf("Examples:\n\n")
 	fmt.Printf("reference URL: https://www.example.com/lorem/ipsum/dolor/sit.html?metus=lectus#at=nostra&unde=omnis\n\n")
 	fmt.Println("url-parser -c host:1 <url>               example.com")
@@ -44,53 +189,461 @@ func usage() {
 	fmt.Println("url-parser -c path:0:2 <url>             /lorem/ipsum")
 	fmt.Println("url-parser -c fragment:unde <url>        unde=omnis")
 	fmt.Println("url-parser -c relativeUrl <url>          /lorem/ipsum/dolor/sit.html?metus=lectus#at=nostra&unde=omnis")
+	fmt.Println("url-parser -c scheme,host -d ' ' <url>   https www.example.com")
+	fmt.Println("url-parser --kv -c host,port <url>       host=www.example.com port=")
 	fmt.Println("url-parser -f {scheme}://{host} <url>    https://www.example.com")
+	fmt.Println("url-parser -s host:0:1=cdn <url>         https://cdn.example.com/lorem/ipsum/dolor/sit.html?metus=lectus#at=nostra&unde=omnis")
+	fmt.Println("url-parser --data -d ' ' data:,Hi        text/plain; charset=US-ASCII false 2")
 }
 
 var (
-	component string
-	format    string
+	parser       urlparser.Parser
+	components   componentFlags
+	delimiter    string
+	keyValues    bool
+	format       string
+	dataInfo     bool
+	payloadFile  string
+	redact       bool
+	redactOpts   urlparser.RedactOptions
+	redactParams string
+
+	raw           bool
+	decode        bool
+	trailingSlash string
+
+	sets      setFlags
+	rulesFile string
+	rules     urlparser.Rules
+
+	workers   int
+	unordered bool
+
+	nullInput       bool
+	nullOutput      bool
+	outputDelimiter = "\n"
+	readErr         error
+
+	payloadOutput io.Writer
+	writeErr      error
 )
 
+type componentFlags []string
+
+func (c *componentFlags) String() string {
+	return strings.Join(*c, ",")
+}
+
+// Set splits the value by commas only when all the parts are valid components, so that names
+// containing commas (query:a,b) still work.
+func (c *componentFlags) Set(value string) error {
+	parts := strings.Split(value, ",")
+	for _, part := range parts {
+		if !isComponent(part) {
+			*c = append(*c, value)
+
+			return nil
+		}
+	}
+
+	*c = append(*c, parts...)
+
+	return nil
+}
+
+func isComponent(component string) bool {
+	var invalidComponentErr *urlparser.InvalidComponentError
+	_, err := urlparser.Component("", component)
+
+	return !errors.As(err, &invalidComponentErr)
+}
+
+type setFlags []string
+
+func (s *setFlags) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *setFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected COMPONENT=VALUE, got %q", value)
+	}
+
+	*s = append(*s, value)
+
+	return nil
+}
+
 func main() {
-	flag.StringVar(&component, "component", "", "")
-	flag.StringVar(&component, "c", "", "")
+	flag.Var(&components, "component", "")
+	flag.Var(&components, "c", "")
+
+	flag.StringVar(&delimiter, "delimiter", "\t", "")
+	flag.StringVar(&delimiter, "d", "\t", "")
+
+	flag.BoolVar(&keyValues, "kv", false, "")
 
 	flag.StringVar(&format, "format", "", "")
 	flag.StringVar(&format, "f", "", "")
 
+	flag.BoolVar(&dataInfo, "data", false, "")
+	flag.StringVar(&payloadFile, "payload", "", "")
+
+	flag.BoolVar(&parser.Whatwg, "whatwg", false, "")
+	flag.BoolVar(&parser.Lenient, "lenient", false, "")
+	flag.StringVar(&parser.AssumeScheme, "assume-scheme", "", "")
+	flag.BoolVar(&raw, "raw", false, "")
+	flag.BoolVar(&decode, "decode", false, "")
+	flag.BoolVar(&parser.TrailingSlashDirs, "trailing-slash-dirs", false, "")
+	flag.StringVar(&trailingSlash, "trailing-slash", "", "")
+
+	flag.BoolVar(&redact, "redact", false, "")
+	flag.BoolVar(&redactOpts.User, "redact-user", false, "")
+	flag.StringVar(&redactParams, "redact-params", strings.Join(urlparser.RedactedParams, ","), "")
+
+	flag.StringVar(&rulesFile, "rules", "", "")
+
+	flag.IntVar(&workers, "workers", 1, "")
+	flag.BoolVar(&unordered, "unordered", false, "")
+
+	flag.BoolVar(&nullInput, "null", false, "")
+	flag.BoolVar(&nullInput, "0", false, "")
+	flag.BoolVar(&nullOutput, "zero-terminated", false, "")
+	flag.BoolVar(&nullOutput, "z", false, "")
+
+	flag.Var(&sets, "set", "")
+	flag.Var(&sets, "s", "")
+
 	flag.Usage = usage
 	flag.Parse()
 
-	if component == "" && format == "" || component != "" && format != "" {
-		fmt.Fprintln(os.Stderr, "You must specify either --component or --format option.")
+	parser.Lenient = parser.Lenient || parser.AssumeScheme != ""
+	switch {
+	case raw && decode:
+		fmt.Fprintln(os.Stderr, "The --raw and --decode options are mutually exclusive.")
+		os.Exit(1)
+	case raw:
+		parser.Encoding = urlparser.RawEncoding
+	case decode:
+		parser.Encoding = urlparser.DecodedEncoding
+	}
+
+	switch trailingSlash {
+	case "":
+	case "add":
+		parser.TrailingSlash = urlparser.AddTrailingSlash
+	case "remove":
+		parser.TrailingSlash = urlparser.RemoveTrailingSlash
+	default:
+		fmt.Fprintln(os.Stderr, "The --trailing-slash option must be either add or remove.")
+		os.Exit(1)
+	}
+
+	redact = redact || redactOpts.User
+	if redactParams != "" {
+		redactOpts.Params = strings.Split(redactParams, ",")
+	}
+
+	if nullOutput {
+		outputDelimiter = "\x00"
+	}
+
+	switch {
+	case workers < 0:
+		fmt.Fprintln(os.Stderr, "The --workers option must not be negative.")
+		os.Exit(1)
+	case workers == 0:
+		workers = runtime.NumCPU()
+	}
+
+	if rulesFile != "" {
+		data, err := os.ReadFile(rulesFile)
+		if err == nil {
+			rules, err = urlparser.ParseRules(data)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to load the rules: "+err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if keyValues && len(components) == 0 {
+		fmt.Fprintln(os.Stderr, "The --kv option requires --component.")
+		os.Exit(1)
+	}
+
+	outputs := 0
+	for _, output := range []bool{len(components) != 0, format != "", dataInfo, payloadFile != ""} {
+		if output {
+			outputs++
+		}
+	}
+
+	if outputs == 0 && !redact && rulesFile == "" && len(sets) == 0 || outputs > 1 {
+		fmt.Fprintln(os.Stderr, "You must specify one of --component, --format, --data or --payload options.")
+		os.Exit(1)
+	}
+
+	var payload *os.File
+	switch payloadFile {
+	case "":
+	case "-":
+		payloadOutput = os.Stdout
+	default:
+		var err error
+		payload, err = os.Create(payloadFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to create the payload file: "+err.Error())
+			os.Exit(1)
+		}
+
+		payloadOutput = payload
+	}
+
+	run()
+
+	if payload != nil {
+		if err := payload.Close(); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+
+	if readErr != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read the input: "+readErr.Error())
+		os.Exit(1)
+	}
+
+	if writeErr != nil {
+		fmt.Fprintln(os.Stderr, "Failed to write the payload: "+writeErr.Error())
 		os.Exit(1)
 	}
+}
+
+func run() {
+	rawUrls := make(chan string, workers)
+	go readUrls(rawUrls)
 
-	if len(flag.Args()) == 0 {
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			process(scanner.Text())
+	if workers == 1 {
+		for rawUrl := range rawUrls {
+			printResult(process(rawUrl))
 		}
+	} else if unordered {
+		processUnordered(rawUrls)
 	} else {
+		processOrdered(rawUrls)
+	}
+}
+
+func readUrls(rawUrls chan<- string) {
+	defer close(rawUrls)
+
+	if len(flag.Args()) != 0 {
 		for _, rawUrl := range flag.Args() {
-			process(rawUrl)
+			rawUrls <- rawUrl
+		}
+
+		return
+	}
+
+	delimiter := byte('\n')
+	if nullInput {
+		delimiter = 0
+	}
+
+	// bufio.Reader is used instead of bufio.Scanner, which can't handle lines longer than 64 KiB
+	reader := bufio.NewReaderSize(os.Stdin, 64*1024)
+	for {
+		rawUrl, err := reader.ReadString(delimiter)
+		if err != nil && err != io.EOF {
+			readErr = err
+
+			return
+		}
+
+		if len(rawUrl) != 0 && rawUrl[len(rawUrl)-1] == delimiter {
+			rawUrl = rawUrl[:len(rawUrl)-1]
+		} else if rawUrl == "" {
+			return
 		}
+
+		if !nullInput {
+			rawUrl = strings.TrimSuffix(rawUrl, "\r")
+		}
+
+		rawUrls <- rawUrl
+
+		if err == io.EOF {
+			return
+		}
+	}
+}
+
+type result struct {
+	output string
+	err    error
+}
+
+// processOrdered processes the URLs in parallel and prints the results in the order of the input.
+func processOrdered(rawUrls <-chan string) {
+	type job struct {
+		rawUrl string
+		result chan result
+	}
+
+	jobs := make(chan job, workers)
+	pending := make(chan chan result, workers*16)
+
+	go func() {
+		for rawUrl := range rawUrls {
+			j := job{rawUrl, make(chan result, 1)}
+			pending <- j.result
+			jobs <- j
+		}
+
+		close(pending)
+		close(jobs)
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				j.result <- process(j.rawUrl)
+			}
+		}()
+	}
+
+	for r := range pending {
+		printResult(<-r)
 	}
 }
 
-func process(rawUrl string) {
-	var result string
+// processUnordered processes the URLs in parallel and prints the results as soon as they are ready.
+func processUnordered(rawUrls <-chan string) {
+	results := make(chan result, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for rawUrl := range rawUrls {
+				results <- process(rawUrl)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		printResult(r)
+	}
+}
+
+func printResult(r result) {
+	if r.err != nil {
+		fmt.Fprintln(os.Stderr, r.err.Error())
+	}
+
+	if payloadOutput != nil {
+		if _, err := io.WriteString(payloadOutput, r.output); err != nil && writeErr == nil {
+			writeErr = err
+		}
+
+		return
+	}
+
+	fmt.Print(r.output, outputDelimiter)
+}
+
+func process(rawUrl string) result {
+	var output string
 	var err error
-	if component == "" {
-		result, err = urlparser.Format(rawUrl, format)
-	} else {
-		result, err = urlparser.Component(rawUrl, component)
+	if redact {
+		rawUrl, err = urlparser.Redact(rawUrl, redactOpts)
 	}
 
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
+	if err == nil && rules != nil {
+		rawUrl, err = parser.ApplyRules(rawUrl, rules)
 	}
 
-	fmt.Println(result)
+	for _, set := range sets {
+		if err != nil {
+			break
+		}
+
+		setComponent, value, _ := strings.Cut(set, "=")
+		rawUrl, err = parser.With(rawUrl, setComponent, value)
+	}
+
+	switch {
+	case err != nil:
+	case len(components) == 1 && !keyValues:
+		output, err = parser.Component(rawUrl, components[0])
+	case len(components) != 0:
+		columns := make([]string, len(components))
+		for i, component := range components {
+			columns[i], err = parser.Component(rawUrl, component)
+			if err != nil {
+				break
+			}
+		}
+
+		if err == nil && keyValues {
+			output = formatKeyValues(columns)
+		} else if err == nil {
+			output = strings.Join(columns, delimiter)
+		}
+	case format != "":
+		output, err = parser.Format(rawUrl, format)
+	case dataInfo, payloadFile != "":
+		var dataUrl *urlparser.DataURL
+		dataUrl, err = parser.ParseDataURL(rawUrl)
+		if err != nil {
+			break
+		}
+
+		if payloadFile != "" {
+			output = string(dataUrl.Data)
+		} else {
+			output = strings.Join([]string{dataUrl.ContentType(), strconv.FormatBool(dataUrl.Base64), strconv.Itoa(len(dataUrl.Data))}, delimiter)
+		}
+	default:
+		output = rawUrl
+	}
+
+	return result{output, err}
+}
+
+// formatKeyValues formats the components in the logfmt style: host=example.com path="/lorem ipsum" port=
+func formatKeyValues(values []string) string {
+	var output strings.Builder
+	for i, component := range components {
+		if i != 0 {
+			output.WriteByte(' ')
+		}
+
+		// names like query:NAME may contain the same characters as the values
+		output.WriteString(quoteKeyValue(component))
+		output.WriteByte('=')
+		output.WriteString(quoteKeyValue(values[i]))
+	}
+
+	return output.String()
+}
+
+func quoteKeyValue(value string) string {
+	if strings.IndexFunc(value, needsQuoting) == -1 {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
+
+func needsQuoting(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r)
 }
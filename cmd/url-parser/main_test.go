@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// generateCorpus generates URLs resembling the ones found in access logs and crawler dumps.
+func generateCorpus(count int) string {
+	random := rand.New(rand.NewSource(1))
+	pick := func(values ...string) string {
+		return values[random.Intn(len(values))]
+	}
+
+	var corpus strings.Builder
+	for i := 0; i < count; i++ {
+		corpus.WriteString(pick("https", "https", "https", "http", "ftp", "postgres"))
+		corpus.WriteString("://")
+
+		if random.Intn(10) == 0 {
+			corpus.WriteString(pick("user", "admin", "j.doe%40example.com") + ":" + pick("secret", "p%40ss") + "@")
+		}
+
+		switch random.Intn(20) {
+		case 0:
+			corpus.WriteString(fmt.Sprintf("%d.%d.%d.%d", random.Intn(256), random.Intn(256), random.Intn(256), random.Intn(256)))
+		case 1:
+			corpus.WriteString("[2001:db8::" + fmt.Sprint(random.Intn(1000)) + "]")
+		default:
+			corpus.WriteString(pick("", "www.", "cdn.", "api.", "static.eu.", "m."))
+			corpus.WriteString(pick("example", "lorem-ipsum", "dolor", "sit-amet", "consectetur"))
+			corpus.WriteString(pick(".com", ".com", ".org", ".net", ".co.uk", ".de", ".com.br", ".io"))
+		}
+
+		if random.Intn(5) == 0 {
+			corpus.WriteString(":" + fmt.Sprint(1024+random.Intn(60000)))
+		}
+
+		for segments := random.Intn(6); segments > 0; segments-- {
+			corpus.WriteString("/" + pick("lorem", "ipsum", "dolor", "v1", "users", fmt.Sprint(random.Intn(100000)), "caf%C3%A9"))
+		}
+
+		if random.Intn(2) == 0 {
+			corpus.WriteString("/" + pick("index", "sit", "amet", "image") + pick(".html", ".php", ".jpg", ".tar.gz", ".js"))
+		}
+
+		if random.Intn(2) == 0 {
+			corpus.WriteString("?" + pick("q", "page", "utm_source") + "=" + pick("lorem+ipsum", "2", "newsletter", "a%20b"))
+
+			for params := random.Intn(4); params > 0; params-- {
+				corpus.WriteString("&" + pick("sort", "lang", "id", "token") + "=" + fmt.Sprint(random.Intn(1000)))
+			}
+		}
+
+		if random.Intn(10) == 0 {
+			corpus.WriteString("#" + pick("top", "section-2", "at=nostra&unde=omnis"))
+		}
+
+		corpus.WriteString("\n")
+	}
+
+	return corpus.String()
+}
+
+func TestFormatKeyValues(t *testing.T) {
+	defer func() {
+		components = nil
+	}()
+
+	tests := []struct {
+		name       string
+		components []string
+		values     []string
+		expected   string
+	}{
+		{
+			name:     "plain",
+			values:   []string{"example.com", "/lorem", "ipsum", "", ""},
+			expected: "host=example.com path=/lorem query:q=ipsum fragment= user=",
+		},
+		{
+			name:     "quoted",
+			values:   []string{"example.com", "/lorem ipsum", `a="b"`, "x\ny", "tést"},
+			expected: `host=example.com path="/lorem ipsum" query:q="a=\"b\"" fragment="x\ny" user=tést`,
+		},
+		{
+			name:     "control characters",
+			values:   []string{"example.com", "/lorem\tipsum", "\x00", "\xff", "\u200b"},
+			expected: `host=example.com path="/lorem\tipsum" query:q="\x00" fragment="\xff" user="\u200b"`,
+		},
+		{
+			name:       "quoted components",
+			components: []string{"query:a b", "fragment:x=y", "host"},
+			values:     []string{"1", "2", "x"},
+			expected:   `"query:a b"=1 "fragment:x=y"=2 host=x`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			components = []string{"host", "path", "query:q", "fragment", "user"}
+			if test.components != nil {
+				components = test.components
+			}
+
+			require.Equal(t, test.expected, formatKeyValues(test.values))
+		})
+	}
+}
+
+func BenchmarkCli(b *testing.B) {
+	corpus := generateCorpus(10000)
+
+	corpusFile := filepath.Join(b.TempDir(), "corpus.txt")
+	if err := os.WriteFile(corpusFile, []byte(corpus), 0o600); err != nil {
+		b.Fatal(err)
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer devNull.Close()
+
+	stdin, stdout, stderr := os.Stdin, os.Stdout, os.Stderr
+	defer func() {
+		os.Stdin, os.Stdout, os.Stderr = stdin, stdout, stderr
+		components, format, workers, unordered = nil, "", 1, false
+	}()
+
+	os.Stdout, os.Stderr = devNull, devNull
+
+	benchmarks := []struct {
+		name      string
+		component string
+		format    string
+		workers   int
+		unordered bool
+	}{
+		{name: "component host", component: "host"},
+		{name: "component path:0:2", component: "path:0:2"},
+		{name: "component file", component: "file"},
+		{name: "components", component: "scheme,host,path,query:q"},
+		{name: "format", format: "{scheme}://{host}{path} {tld} {query:q}"},
+		{name: "format 4 workers", format: "{scheme}://{host}{path} {tld} {query:q}", workers: 4},
+		{name: "format 4 workers unordered", format: "{scheme}://{host}{path} {tld} {query:q}", workers: 4, unordered: true},
+	}
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			format, unordered = benchmark.format, benchmark.unordered
+			components = nil
+			if benchmark.component != "" {
+				components = strings.Split(benchmark.component, ",")
+			}
+
+			workers = 1
+			if benchmark.workers != 0 {
+				workers = benchmark.workers
+			}
+
+			b.SetBytes(int64(len(corpus)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				os.Stdin, err = os.Open(corpusFile)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				run()
+
+				os.Stdin.Close()
+			}
+
+			if readErr != nil {
+				b.Fatal(readErr)
+			}
+		})
+	}
+}
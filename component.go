@@ -1,7 +1,9 @@
 package urlparser
 
 import (
+	"net/netip"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 
@@ -26,22 +28,79 @@ const (
 	Ext         = "ext"
 	RelativeUrl = "relativeUrl"
 
+	EffectivePort     = "effectivePort"
+	HostPortEffective = "hostPortEffective"
+
+	Hosts    = "hosts"
+	Database = "database"
+	Options  = "options"
+
+	HostType    = "hostType"
+	IpVersion   = "ipVersion"
+	IsLoopback  = "isLoopback"
+	IsPrivate   = "isPrivate"
+	IsLinkLocal = "isLinkLocal"
+	IsMulticast = "isMulticast"
+
+	Ipv4             = "ipv4"
+	IsObfuscatedIpv4 = "isObfuscatedIpv4"
+
+	RawPath      = "rawPath"
+	SegmentCount = "segmentCount"
+	Segment      = "segment"
+
+	Matrix    = "matrix"
+	PlainPath = "plainPath"
+
+	LastExt  = "lastExt"
+	FileStem = "fileStem"
+	MimeType = "mimeType"
+
+	Dirname          = "dirname"
+	Basename         = "basename"
+	HasTrailingSlash = "hasTrailingSlash"
+
+	RawAuth     = "rawAuth"
+	RawUser     = "rawUser"
+	RawPassword = "rawPassword"
+
+	Opaque = "opaque"
+
+	MailtoRecipients = "mailtoRecipients"
+	MailtoHeaders    = "mailtoHeaders"
+	UrnNamespace     = "urnNamespace"
+	UrnNss           = "urnNss"
+	DataMediaType    = "dataMediaType"
+	DataCharset      = "dataCharset"
+	DataBase64       = "dataBase64"
+	DataSize         = "dataSize"
+
 	boundedHostPrefix     = Host + ":"
 	boundedHostPortPrefix = HostPort + ":"
 	boundedPathPrefix     = Path + ":"
 	singleQueryPrefix     = Query + ":"
 	singleFragmentPrefix  = Fragment + ":"
+	pathSegmentPrefix     = Segment + ":"
+	matrixParamPrefix     = Matrix + ":"
 
 	halfMaxInt = int(^(uint(0))>>1) / 2
 )
 
 func Component(rawUrl string, component string) (string, error) {
-	parsedUrl, err := url.Parse(rawUrl)
+	return Parser{}.Component(rawUrl, component)
+}
+
+func (p Parser) Component(rawUrl string, component string) (string, error) {
+	if result, ok := p.getScannedComponent(rawUrl, component); ok {
+		return result, nil
+	}
+
+	parsedUrl, err := p.parse(rawUrl)
 	if err != nil {
 		return "", err
 	}
 
-	return getComponent(parsedUrl, component)
+	return p.getEncodedComponent(parsedUrl, component)
 }
 
 func PartialHost(from, count int) string {
@@ -68,6 +127,18 @@ func PartialPathFrom(from int) string {
 	return boundedPathPrefix + strconv.Itoa(from)
 }
 
+func PathSegment(index int) string {
+	return pathSegmentPrefix + strconv.Itoa(index)
+}
+
+func PathSegmentFromEnd(index int) string {
+	return pathSegmentPrefix + "-" + strconv.Itoa(index)
+}
+
+func MatrixParam(segment int, name string) string {
+	return matrixParamPrefix + strconv.Itoa(segment) + ":" + name
+}
+
 func SingleQuery(name string) string {
 	return singleQueryPrefix + name
 }
@@ -76,17 +147,48 @@ func SingleFragment(name string) string {
 	return singleFragmentPrefix + name
 }
 
-func getComponent(parsedUrl *url.URL, component string) (string, error) {
+func (p Parser) getComponent(parsedUrl *urlParts, component string) (string, error) {
 	var result string
 
 	switch true {
 	case component == Scheme:
 		result = parsedUrl.Scheme
+	case component == Opaque:
+		result = parsedUrl.Opaque
+	case component == MailtoRecipients:
+		result = getMailtoRecipients(parsedUrl.URL)
+	case component == MailtoHeaders:
+		result = getMailtoHeaders(parsedUrl.URL)
+	case component == UrnNamespace:
+		result, _ = getUrnParts(parsedUrl.URL)
+	case component == UrnNss:
+		_, result = getUrnParts(parsedUrl.URL)
+	case component == DataMediaType:
+		if data, ok := parseDataUrl(parsedUrl.URL); ok {
+			result = data.mediaType
+		}
+	case component == DataCharset:
+		if data, ok := parseDataUrl(parsedUrl.URL); ok {
+			result = data.params["charset"]
+		}
+	case component == DataBase64:
+		if data, ok := parseDataUrl(parsedUrl.URL); ok {
+			result = strconv.FormatBool(data.base64)
+		}
+	case component == DataSize:
+		if data, ok := parseDataUrl(parsedUrl.URL); ok {
+			payload, err := data.decode()
+			if err != nil {
+				return "", err
+			}
+
+			result = strconv.Itoa(len(payload))
+		}
 	case component == Authority:
 		if parsedUrl.User == nil {
-			result = parsedUrl.Host
+			result = joinEscapedHosts(parsedUrl.hosts)
 		} else {
-			result = parsedUrl.User.String() + "@" + parsedUrl.Host
+			result = parsedUrl.User.String() + "@" + joinEscapedHosts(parsedUrl.hosts)
 		}
 	case component == Auth:
 		result = parsedUrl.User.String()
@@ -94,31 +196,97 @@ func getComponent(parsedUrl *url.URL, component string) (string, error) {
 		result = parsedUrl.User.Username()
 	case component == Password:
 		result, _ = parsedUrl.User.Password()
+	case component == RawAuth:
+		result = parsedUrl.rawUserinfo
+	case component == RawUser:
+		result, _, _ = strings.Cut(parsedUrl.rawUserinfo, ":")
+	case component == RawPassword:
+		_, result, _ = strings.Cut(parsedUrl.rawUserinfo, ":")
 	case component == HostPort:
 		result = parsedUrl.Host
 	case component == Host:
 		result = parsedUrl.Hostname()
+	case component == HostType:
+		result = getHostType(parsedUrl.Hostname())
+	case component == IpVersion:
+		result = getIpVersion(parsedUrl.Hostname())
+	case component == IsLoopback:
+		result = getIpFlag(parsedUrl.Hostname(), netip.Addr.IsLoopback)
+	case component == IsPrivate:
+		result = getIpFlag(parsedUrl.Hostname(), netip.Addr.IsPrivate)
+	case component == IsLinkLocal:
+		result = getIpFlag(parsedUrl.Hostname(), func(addr netip.Addr) bool {
+			return addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast()
+		})
+	case component == IsMulticast:
+		result = getIpFlag(parsedUrl.Hostname(), netip.Addr.IsMulticast)
+	case component == Ipv4:
+		result = getCanonicalIpv4(parsedUrl.Hostname())
+	case component == IsObfuscatedIpv4:
+		// the WHATWG parser already turns the obfuscated hosts into dotted quads
+		host := parsedUrl.inputHost
+		if host == "" {
+			host = parsedUrl.Hostname()
+		}
+
+		result = isObfuscatedIpv4(host)
 	case component == Tld:
 		host := parsedUrl.Hostname()
 		if host == "" {
 			break
 		}
 
-		tld, _ := publicsuffix.PublicSuffix(host)
-		_, err := strconv.Atoi(tld)
-		if err == nil {
-			break // ipv4
+		if _, ok := parseHostAddr(host); ok {
+			break // ipv4 or ipv6
 		}
 
-		if strings.Contains(host, ":") {
-			break // ipv6
-		}
-
-		result = tld
+		result, _ = publicsuffix.PublicSuffix(host)
 	case component == Port:
 		result = parsedUrl.Port()
+	case component == EffectivePort:
+		result = getEffectivePort(parsedUrl.URL)
+	case component == HostPortEffective:
+		result = parsedUrl.Hostname()
+		if strings.Contains(result, ":") {
+			result = "[" + result + "]"
+		}
+
+		port := getEffectivePort(parsedUrl.URL)
+		if port != "" {
+			result += ":" + port
+		}
+	case component == Hosts:
+		result = joinEscapedHosts(parsedUrl.hosts)
 	case component == Path:
 		result = parsedUrl.Path
+	case component == RawPath:
+		result = parsedUrl.EscapedPath()
+	case component == SegmentCount:
+		result = strconv.Itoa(len(getPathSegments(parsedUrl.URL)))
+	case component == PlainPath:
+		segments := getPathSegments(parsedUrl.URL)
+		if len(segments) == 0 {
+			break
+		}
+
+		for i, segment := range segments {
+			segments[i] = stripMatrixParams(segment)
+		}
+
+		result = unescapePathSegment("/" + strings.Join(segments, "/"))
+	case component == Matrix:
+		var params []string
+		for _, segment := range getPathSegments(parsedUrl.URL) {
+			if i := strings.Index(segment, ";"); i != -1 {
+				params = append(params, segment[i+1:])
+			}
+		}
+
+		result = strings.Join(params, ";")
+	case component == Database:
+		result = getDatabase(parsedUrl.URL)
+	case component == Options:
+		result = parsedUrl.Query().Encode()
 	case component == Query:
 		result = parsedUrl.RawQuery
 	case component == Fragment:
@@ -130,80 +298,120 @@ func getComponent(parsedUrl *url.URL, component string) (string, error) {
 			result = "/"
 		}
 	case component == File:
-		if strings.Contains(parsedUrl.Path, "/") {
-			result = parsedUrl.Path[strings.LastIndex(parsedUrl.Path, "/")+1 : len(parsedUrl.Path)]
-		} else {
-			result = parsedUrl.Path
+		segments := getPathSegments(parsedUrl.URL)
+		if len(segments) != 0 {
+			result = unescapePathSegment(stripMatrixParams(segments[len(segments)-1]))
 		}
 
-		if !strings.Contains(result, ".") {
+		if !p.TrailingSlashDirs && !strings.Contains(result, ".") {
 			result = ""
 		}
-	case component == Ext:
-		result, _ = getComponent(parsedUrl, "file")
-		if result != "" {
-			result = result[strings.Index(result, ".")+1:]
+	case component == Dirname:
+		if parsedUrl.Path != "" {
+			// like POSIX dirname, ignore the trailing slashes
+			result = strings.TrimRight(parsedUrl.Path, "/")
+			if result == "" {
+				result = "/"
+			}
+
+			result = path.Dir(result)
 		}
-	case component == RelativeUrl:
+	case component == Basename:
 		if parsedUrl.Path != "" {
-			result += parsedUrl.Path
+			result = path.Base(parsedUrl.Path)
 		}
+	case component == HasTrailingSlash:
+		result = strconv.FormatBool(strings.HasSuffix(parsedUrl.Path, "/"))
+	case component == Ext:
+		result, _ = p.getComponent(parsedUrl, "file")
+		result = getExt(result)
+	case component == LastExt:
+		result, _ = p.getComponent(parsedUrl, "file")
+		result = getLastExt(result)
+	case component == FileStem:
+		result, _ = p.getComponent(parsedUrl, "file")
+		result = getFileStem(result)
+	case component == MimeType:
+		result, _ = p.getComponent(parsedUrl, "file")
+		result = getMimeType(result)
+	case component == RelativeUrl:
+		result = parsedUrl.EscapedPath()
 
-		if parsedUrl.RawQuery != "" {
+		if parsedUrl.RawQuery != "" || parsedUrl.ForceQuery {
 			result += "?" + parsedUrl.RawQuery
 		}
 
 		if parsedUrl.Fragment != "" {
-			result += "#" + parsedUrl.Fragment
+			result += "#" + parsedUrl.EscapedFragment()
 		}
 	case strings.HasPrefix(component, boundedHostPrefix):
 		if parsedUrl.Host == "" {
 			break
 		}
 
-		host := parsedUrl.Hostname()
+		hostParts, err := getSliceWithinBounds(component, splitHost(parsedUrl.Hostname()))
+		if err != nil {
+			return "", err
+		}
 
-		tld, _ := publicsuffix.PublicSuffix(host)
-		if tld != "" {
-			host = host[0:len(host)-len(tld)] + "~~~"
+		result = strings.Join(hostParts, ".")
+	case strings.HasPrefix(component, boundedHostPortPrefix):
+		var err error
+		result, err = p.getComponent(parsedUrl, Host+component[len(HostPort):])
+		if err != nil {
+			return "", newInvalidComponentErr(component)
 		}
 
-		hostParts, err := getSliceWithinBounds(component, strings.Split(host, "."))
+		port := parsedUrl.Port()
+		if port != "" {
+			result += ":" + port
+		}
+	case strings.HasPrefix(component, boundedPathPrefix):
+		slice, err := getSliceWithinBounds(component, getPathSegments(parsedUrl.URL))
 		if err != nil {
 			return "", err
 		}
 
-		if len(hostParts) == 0 {
+		for i, segment := range slice {
+			slice[i] = stripMatrixParams(segment)
+		}
+
+		if len(slice) == 0 {
 			break
 		}
 
-		if hostParts[len(hostParts)-1] == "~~~" {
-			hostParts[len(hostParts)-1] = tld
+		result = "/" + strings.Join(slice, "/")
+	case strings.HasPrefix(component, pathSegmentPrefix):
+		if strings.Count(component, ":") != 1 || component == pathSegmentPrefix {
+			return "", newInvalidComponentErr(component)
 		}
 
-		result = strings.Join(hostParts, ".")
-	case strings.HasPrefix(component, boundedHostPortPrefix):
-		result, _ = getComponent(parsedUrl, "host"+component[8:])
-		port := parsedUrl.Port()
-		if port != "" {
-			result += ":" + port
+		slice, err := getSliceWithinBounds(component+":1", getPathSegments(parsedUrl.URL))
+		if err != nil {
+			return "", newInvalidComponentErr(component)
 		}
-	case strings.HasPrefix(component, boundedPathPrefix):
-		if parsedUrl.Path == "" {
+
+		if len(slice) == 0 {
 			break
 		}
 
-		splitted := strings.Split(parsedUrl.Path, "/")
-		slice, err := getSliceWithinBounds(component, splitted[1:])
+		result = unescapePathSegment(stripMatrixParams(slice[0]))
+	case strings.HasPrefix(component, matrixParamPrefix):
+		splitted := strings.SplitN(component, ":", 3)
+		if len(splitted) != 3 || splitted[1] == "" || splitted[2] == "" {
+			return "", newInvalidComponentErr(component)
+		}
+
+		slice, err := getSliceWithinBounds(Matrix+":"+splitted[1]+":1", getPathSegments(parsedUrl.URL))
 		if err != nil {
-			return "", err
+			return "", newInvalidComponentErr(component)
 		}
 
 		if len(slice) == 0 {
 			break
 		}
 
-		result = "/" + strings.Join(slice, "/")
+		result = getMatrixParams(slice[0]).Get(splitted[2])
 	case strings.HasPrefix(component, singleQueryPrefix):
 		result = parsedUrl.Query().Get(component[6:])
 	case strings.HasPrefix(component, singleFragmentPrefix):
@@ -222,41 +430,122 @@ func getComponent(parsedUrl *url.URL, component string) (string, error) {
 	return result, nil
 }
 
+// getPathSegments splits the escaped path, so that encoded slashes (%2F) stay inside their segments.
+func getPathSegments(parsedUrl *url.URL) []string {
+	path := parsedUrl.EscapedPath()
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+func unescapePathSegment(segment string) string {
+	unescaped, err := url.PathUnescape(segment)
+	if err != nil {
+		return segment
+	}
+
+	return unescaped
+}
+
+// stripMatrixParams removes the matrix parameters (;name=value) from an escaped path segment.
+func stripMatrixParams(segment string) string {
+	if i := strings.Index(segment, ";"); i != -1 {
+		return segment[:i]
+	}
+
+	return segment
+}
+
+func getMatrixParams(segment string) url.Values {
+	params := url.Values{}
+
+	splitted := strings.Split(segment, ";")
+	for _, param := range splitted[1:] {
+		if param == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(param, "=")
+		params.Add(unescapePathSegment(name), unescapePathSegment(value))
+	}
+
+	return params
+}
+
+// joinEscapedHosts joins the hosts escaped the way url.URL.String does it, e.g. for IPv6 zones ([fe80::1%25en0]).
+func joinEscapedHosts(hosts []string) string {
+	escaped := make([]string, len(hosts))
+	for i, host := range hosts {
+		escaped[i] = strings.TrimPrefix((&url.URL{Host: host}).String(), "//")
+	}
+
+	return strings.Join(escaped, ",")
+}
+
+// splitHost splits the host into its labels, keeping the public suffix (co.uk) as a single label.
+func splitHost(host string) []string {
+	tld, _ := publicsuffix.PublicSuffix(host)
+	if tld == "" || tld == host {
+		return strings.Split(host, ".")
+	}
+
+	return append(strings.Split(host[:len(host)-len(tld)-1], "."), tld)
+}
+
 func getSliceWithinBounds(component string, orig []string) ([]string, error) {
+	start, end, err := getBoundsWithin(component, len(orig))
+	if err != nil {
+		return nil, err
+	}
+
+	return orig[start:end], nil
+}
+
+// getBoundsWithin returns the start and end index of the bounded component (path:x:y) within the given length.
+func getBoundsWithin(component string, length int) (int, int, error) {
 	var err error
 	var start, count int
 	var fromEnd = false
 
-	splitted := strings.SplitN(component, ":", 3)
-	if splitted[1][0] == '-' {
+	_, bounds, _ := strings.Cut(component, ":")
+	rawStart, rawCount, hasCount := strings.Cut(bounds, ":")
+	if rawStart == "" {
+		return 0, 0, newInvalidComponentErr(component)
+	}
+
+	if rawStart[0] == '-' {
 		fromEnd = true
-		splitted[1] = splitted[1][1:]
+		rawStart = rawStart[1:]
 	}
 
-	start, err = strconv.Atoi(splitted[1])
+	start, err = strconv.Atoi(rawStart)
 	if err != nil || start < 0 {
-		return nil, newInvalidComponentErr(component)
+		return 0, 0, newInvalidComponentErr(component)
 	}
 
-	if len(splitted) == 3 {
-		count, err = strconv.Atoi(splitted[2])
+	if hasCount {
+		count, err = strconv.Atoi(rawCount)
 		if err != nil || count < 0 {
-			return nil, newInvalidComponentErr(component)
+			return 0, 0, newInvalidComponentErr(component)
 		}
 	} else {
 		count = halfMaxInt
 	}
 
-	if start > len(orig) {
-		return []string{}, nil
+	if start > length {
+		return length, length, nil
 	}
 
+	// avoid overflowing start+count
+	count = min(count, length)
+
 	if fromEnd {
-		return orig[max(0, len(orig)-count) : len(orig)-start], nil
+		return max(0, length-start-count), length - start, nil
 	}
 
-	return orig[start:min(len(orig), start+count)], nil
-
+	return start, min(length, start+count), nil
 }
 
 func min(x, y int) int {
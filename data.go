@@ -0,0 +1,174 @@
+package urlparser
+
+import (
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultDataMediaType = "text/plain"
+	defaultDataCharset   = "US-ASCII"
+)
+
+// DataURL is the decoded data:[<media type>][;<name>=<value>]...[;base64],<data> URL (RFC 2397).
+type DataURL struct {
+	// MediaType is lowercased and defaults to text/plain (with Params charset=US-ASCII).
+	MediaType string
+	Params    map[string]string
+	Base64    bool
+	Data      []byte
+}
+
+func ParseDataURL(rawUrl string) (*DataURL, error) {
+	return Parser{}.ParseDataURL(rawUrl)
+}
+
+// ParseDataURL decodes the payload of the data URL, either base64 or percent-encoded.
+func (p Parser) ParseDataURL(rawUrl string) (*DataURL, error) {
+	parsedUrl, err := p.parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, ok := parseDataUrl(parsedUrl.URL)
+	if !ok {
+		return nil, errors.New("not a data URL: " + rawUrl)
+	}
+
+	data, err := parsed.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataURL{
+		MediaType: parsed.mediaType,
+		Params:    parsed.params,
+		Base64:    parsed.base64,
+		Data:      data,
+	}, nil
+}
+
+// ContentType returns the media type with its parameters, as in the Content-Type header.
+func (d *DataURL) ContentType() string {
+	if contentType := mime.FormatMediaType(d.MediaType, d.Params); contentType != "" {
+		return contentType
+	}
+
+	return d.MediaType
+}
+
+// dataUrl holds the parts of data:[<media type>][;<name>=<value>]...[;base64],<data> URLs (RFC 2397).
+type dataUrl struct {
+	mediaType string
+	params    map[string]string
+	base64    bool
+	// data is the payload as it appears in the URL, still percent-encoded
+	data string
+}
+
+// parseDataUrl splits the data URL the way browsers do: the media type defaults to text/plain;charset=US-ASCII
+// and the payload extends to the fragment, including any "?" (which url.Parse takes for the query).
+func parseDataUrl(parsedUrl *url.URL) (dataUrl, bool) {
+	var result dataUrl
+	if parsedUrl.Scheme != "data" || parsedUrl.Opaque == "" && parsedUrl.Path == "" {
+		return result, false
+	}
+
+	rest := parsedUrl.Opaque
+	if rest == "" {
+		rest = parsedUrl.EscapedPath()
+	}
+
+	if parsedUrl.RawQuery != "" || parsedUrl.ForceQuery {
+		rest += "?" + parsedUrl.RawQuery
+	}
+
+	header, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return result, false
+	}
+
+	result.data = data
+
+	if unescaped, err := url.PathUnescape(header); err == nil {
+		header = unescaped
+	}
+	params := strings.Split(header, ";")
+	if last := strings.TrimSpace(params[len(params)-1]); len(params) > 1 && strings.EqualFold(last, "base64") {
+		result.base64 = true
+		params = params[:len(params)-1]
+	}
+
+	result.mediaType = strings.ToLower(strings.TrimSpace(params[0]))
+	result.params = map[string]string{}
+	for _, param := range params[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+			value = unquoted
+		}
+
+		if _, ok := result.params[name]; !ok {
+			result.params[name] = value
+		}
+	}
+
+	if !strings.Contains(result.mediaType, "/") {
+		result.mediaType = defaultDataMediaType
+		if _, ok := result.params["charset"]; !ok {
+			result.params["charset"] = defaultDataCharset
+		}
+	}
+
+	return result, true
+}
+
+// decode percent-decodes the payload, keeping invalid escapes as they are, and then decodes base64 if flagged.
+// Like in browsers, whitespace and missing padding in base64 are tolerated.
+func (d dataUrl) decode() ([]byte, error) {
+	decoded := make([]byte, 0, len(d.data))
+	for i := 0; i < len(d.data); i++ {
+		if d.data[i] == '%' && i+2 < len(d.data) && isHex(d.data[i+1]) && isHex(d.data[i+2]) {
+			value, _ := strconv.ParseUint(d.data[i+1:i+3], 16, 8)
+			decoded = append(decoded, byte(value))
+			i += 2
+
+			continue
+		}
+
+		decoded = append(decoded, d.data[i])
+	}
+
+	if !d.base64 {
+		return decoded, nil
+	}
+
+	encoded := decoded[:0]
+	for _, c := range decoded {
+		if c != ' ' && c != '\t' && c != '\n' && c != '\f' && c != '\r' {
+			encoded = append(encoded, c)
+		}
+	}
+
+	if len(encoded)%4 == 0 {
+		for i := 0; i < 2 && len(encoded) > 0 && encoded[len(encoded)-1] == '='; i++ {
+			encoded = encoded[:len(encoded)-1]
+		}
+	}
+
+	result := make([]byte, base64.RawStdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.RawStdEncoding.Decode(result, encoded)
+	if err != nil {
+		return nil, errors.New("invalid base64 data: " + err.Error())
+	}
+
+	return result[:n], nil
+}
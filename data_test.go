@@ -0,0 +1,115 @@
+package urlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDataURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawUrl    string
+		expected  *DataURL
+		expectErr bool
+	}{
+		{
+			name:   "base64",
+			rawUrl: "data:image/gif;base64,R0lGODlhAQABAAAAACw=",
+			expected: &DataURL{
+				MediaType: "image/gif",
+				Params:    map[string]string{},
+				Base64:    true,
+				Data:      []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00,"),
+			},
+		},
+		{
+			name:   "percent-encoded",
+			rawUrl: "data:text/html;charset=UTF-8,%3Cscript%3Ealert(1)%3C/script%3E",
+			expected: &DataURL{
+				MediaType: "text/html",
+				Params:    map[string]string{"charset": "UTF-8"},
+				Data:      []byte("<script>alert(1)</script>"),
+			},
+		},
+		{
+			name:   "defaults",
+			rawUrl: "data:,Hello%2C%20World!",
+			expected: &DataURL{
+				MediaType: "text/plain",
+				Params:    map[string]string{"charset": "US-ASCII"},
+				Data:      []byte("Hello, World!"),
+			},
+		},
+		{
+			name:   "charset without media type",
+			rawUrl: "data:;charset=utf-8;BASE64,Y2Fmw6k",
+			expected: &DataURL{
+				MediaType: "text/plain",
+				Params:    map[string]string{"charset": "utf-8"},
+				Base64:    true,
+				Data:      []byte("café"),
+			},
+		},
+		{
+			name:   "quoted parameter and invalid escape",
+			rawUrl: `data:application/json;name="a%20b.json",{"a":%ZZ}?b#c`,
+			expected: &DataURL{
+				MediaType: "application/json",
+				Params:    map[string]string{"name": "a b.json"},
+				Data:      []byte(`{"a":%ZZ}?b`),
+			},
+		},
+		{
+			name:   "invalid escape in parameter",
+			rawUrl: "data:image/png;name=100%,x",
+			expected: &DataURL{
+				MediaType: "image/png",
+				Params:    map[string]string{"name": "100%"},
+				Data:      []byte("x"),
+			},
+		},
+		{
+			name:      "invalid base64",
+			rawUrl:    "data:;base64,Y2Fmw6*",
+			expectErr: true,
+		},
+		{
+			name:      "not a data url",
+			rawUrl:    "https://example.com/image.png",
+			expectErr: true,
+		},
+		{
+			name:      "without comma",
+			rawUrl:    "data:text/plain",
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			dataUrl, err := ParseDataURL(test.rawUrl)
+
+			if test.expectErr {
+				require.Error(err)
+			} else {
+				require.NoError(err)
+			}
+
+			require.Equal(test.expected, dataUrl)
+		})
+	}
+}
+
+func TestDataURLContentType(t *testing.T) {
+	require := require.New(t)
+
+	dataUrl, err := ParseDataURL("data:text/plain;charset=utf-8;name=lorem%20ipsum.txt,lorem")
+	require.NoError(err)
+	require.Equal(`text/plain; charset=utf-8; name="lorem ipsum.txt"`, dataUrl.ContentType())
+
+	dataUrl, err = ParseDataURL("data:text/plain;lorem%20ipsum=dolor,sit")
+	require.NoError(err)
+	require.Equal("text/plain", dataUrl.ContentType())
+}
@@ -0,0 +1,42 @@
+package urlparser
+
+import (
+	"net/url"
+	"strings"
+)
+
+type DSN struct {
+	Scheme   string
+	User     string
+	Password string
+	Hosts    []string
+	Database string
+	Options  url.Values
+}
+
+func ParseDSN(rawDsn string) (*DSN, error) {
+	parsedUrl, err := parseUrl(rawDsn)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := &DSN{
+		Scheme:   parsedUrl.Scheme,
+		User:     parsedUrl.User.Username(),
+		Hosts:    parsedUrl.hosts,
+		Database: getDatabase(parsedUrl.URL),
+		Options:  parsedUrl.Query(),
+	}
+	dsn.Password, _ = parsedUrl.User.Password()
+
+	return dsn, nil
+}
+
+func getDatabase(parsedUrl *url.URL) string {
+	database := strings.TrimPrefix(parsedUrl.Path, "/")
+	if i := strings.Index(database, "/"); i != -1 {
+		database = database[:i]
+	}
+
+	return database
+}
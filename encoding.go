@@ -0,0 +1,186 @@
+package urlparser
+
+import (
+	"net/url"
+	"strings"
+)
+
+type Encoding int
+
+const (
+	// DefaultEncoding decodes components holding a single value and encodes the others.
+	DefaultEncoding Encoding = iota
+	// RawEncoding prints components exactly as they appeared in the URL, where possible.
+	RawEncoding
+	// DecodedEncoding percent-decodes all components.
+	DecodedEncoding
+
+	encodingSeparator = "!"
+	rawSuffix         = "raw"
+	decodedSuffix     = "decoded"
+)
+
+// getEncodedComponent handles the encoding suffix of the component (path!raw, query!decoded),
+// falling back to the encoding of the parser.
+func (p Parser) getEncodedComponent(parsedUrl *urlParts, component string) (string, error) {
+	// other exclamation marks are left alone, as they may be a part of names (query:a!b)
+	encoding := p.Encoding
+	switch {
+	case strings.HasSuffix(component, encodingSeparator+rawSuffix):
+		encoding = RawEncoding
+		component = strings.TrimSuffix(component, encodingSeparator+rawSuffix)
+	case strings.HasSuffix(component, encodingSeparator+decodedSuffix):
+		encoding = DecodedEncoding
+		component = strings.TrimSuffix(component, encodingSeparator+decodedSuffix)
+	}
+
+	if encoding == RawEncoding {
+		if result, ok := p.getRawComponent(parsedUrl, component); ok {
+			return result, nil
+		}
+	}
+
+	result, err := p.getComponent(parsedUrl, component)
+	if err != nil || encoding != DecodedEncoding || !isEncodedComponent(component) {
+		return result, err
+	}
+
+	var decoded string
+	if component == Query || component == Options {
+		decoded, err = url.QueryUnescape(result)
+	} else {
+		decoded, err = url.PathUnescape(result)
+	}
+
+	if err != nil {
+		return result, nil
+	}
+
+	return decoded, nil
+}
+
+// isEncodedComponent checks whether the component is returned percent-encoded by default. The others
+// are already decoded and decoding them again would corrupt them (%2541 would become A instead of %41).
+func isEncodedComponent(component string) bool {
+	switch true {
+	case component == Authority, component == Auth, component == Query, component == Options,
+		component == RelativeUrl, component == RawPath, component == Hosts, component == Matrix,
+		component == Opaque, component == MailtoHeaders, component == UrnNss,
+		component == RawAuth, component == RawUser, component == RawPassword:
+		return true
+	case strings.HasPrefix(component, boundedPathPrefix):
+		return true
+	}
+
+	return false
+}
+
+// getRawComponent returns the component exactly as it appeared in the URL. Components which are never
+// encoded (scheme, port, ...) or which are computed (tld, hostType, ...) have no raw variant.
+func (p Parser) getRawComponent(parsedUrl *urlParts, component string) (string, bool) {
+	var result string
+
+	switch true {
+	case component == Authority:
+		result = parsedUrl.rawAuthority
+	case component == Auth:
+		result = parsedUrl.rawUserinfo
+	case component == User:
+		result, _, _ = strings.Cut(parsedUrl.rawUserinfo, ":")
+	case component == Password:
+		_, result, _ = strings.Cut(parsedUrl.rawUserinfo, ":")
+	case component == Hosts:
+		result = parsedUrl.rawHosts
+	case component == HostPort:
+		result, _, _ = strings.Cut(parsedUrl.rawHosts, ",")
+	case component == Host:
+		hostPort, _, _ := strings.Cut(parsedUrl.rawHosts, ",")
+		result = (&url.URL{Host: hostPort}).Hostname()
+	case component == Path:
+		result = parsedUrl.rawPath
+	case component == Fragment:
+		result = parsedUrl.rawFragment
+	case component == RelativeUrl:
+		result = parsedUrl.rawRelativeUrl
+	case component == File:
+		segments := strings.Split(parsedUrl.rawPath, "/")
+		result = stripMatrixParams(segments[len(segments)-1])
+		if !p.TrailingSlashDirs && !strings.Contains(result, ".") {
+			result = ""
+		}
+	case strings.HasPrefix(component, pathSegmentPrefix):
+		if component == pathSegmentPrefix || strings.Count(component, ":") != 1 {
+			return "", false
+		}
+
+		var segments []string
+		if parsedUrl.rawPath != "" {
+			segments = strings.Split(strings.TrimPrefix(parsedUrl.rawPath, "/"), "/")
+		}
+
+		slice, err := getSliceWithinBounds(component+":1", segments)
+		if err != nil {
+			return "", false
+		}
+
+		if len(slice) != 0 {
+			result = stripMatrixParams(slice[0])
+		}
+	case strings.HasPrefix(component, singleQueryPrefix):
+		result = getRawParam(parsedUrl.RawQuery, component[len(singleQueryPrefix):])
+	case strings.HasPrefix(component, singleFragmentPrefix):
+		if strings.Contains(parsedUrl.rawFragment, "=") {
+			result = getRawParam(parsedUrl.rawFragment, component[len(singleFragmentPrefix):])
+		}
+	default:
+		return "", false
+	}
+
+	return result, true
+}
+
+// setRawParts stores the parts of the URL exactly as they appear in the input, before any decoding.
+func setRawParts(parsedUrl *urlParts, rawUrl string) {
+	rest := rawUrl
+	if i := strings.Index(rest, "#"); i != -1 {
+		parsedUrl.rawFragment = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	if i := strings.Index(rest, "?"); i != -1 {
+		rest = rest[:i]
+	}
+
+	pathStart := 0
+	if authorityStart, authorityEnd, ok := findAuthority(rest); ok {
+		parsedUrl.rawAuthority = rest[authorityStart:authorityEnd]
+		parsedUrl.rawHosts = parsedUrl.rawAuthority[strings.LastIndex(parsedUrl.rawAuthority, "@")+1:]
+		pathStart = authorityEnd
+	} else if parsedUrl.Opaque != "" {
+		// opaque URLs have no path, only the query and the fragment follow the opaque part
+		pathStart = len(rest)
+	} else if parsedUrl.Scheme != "" && len(parsedUrl.Scheme) < len(rest) {
+		pathStart = len(parsedUrl.Scheme) + 1
+	}
+
+	parsedUrl.rawUserinfo, _ = getRawUserinfo(rest)
+	parsedUrl.rawPath = rest[pathStart:]
+	parsedUrl.rawRelativeUrl = rawUrl[pathStart:]
+}
+
+func getRawParam(rawParams string, name string) string {
+	for _, param := range strings.Split(rawParams, "&") {
+		rawName, value, _ := strings.Cut(param, "=")
+
+		decodedName, err := url.QueryUnescape(rawName)
+		if err != nil {
+			decodedName = rawName
+		}
+
+		if decodedName == name {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,339 @@
+package urlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const encodedUrl = "http://us%65r:p%40ss@ex%C3%A4mple.com:8080/lorem%20ipsum/a%2Fb/d%C3%A9j%C3%A0.html" +
+	"?q=a%2Bb+c&n%61me=%7Evalue#at=no%20stra&unde=omnis"
+
+func TestEncodedComponent(t *testing.T) {
+	tests := []struct {
+		name          string
+		rawUrl        string
+		encoding      Encoding
+		whatwg        bool
+		trailingSlash TrailingSlash
+		component     string
+		expected      string
+		expectErr     bool
+	}{
+		// ----------- default
+		{
+			name:      "default path",
+			component: "path",
+			expected:  "/lorem ipsum/a/b/déjà.html",
+		},
+		{
+			name:      "default query",
+			component: "query",
+			expected:  "q=a%2Bb+c&n%61me=%7Evalue",
+		},
+		{
+			name:      "default relativeUrl",
+			component: "relativeUrl",
+			expected:  "/lorem%20ipsum/a%2Fb/d%C3%A9j%C3%A0.html?q=a%2Bb+c&n%61me=%7Evalue#at=no%20stra&unde=omnis",
+		},
+		// ----------- raw suffix
+		{
+			name:      "raw suffix path",
+			component: "path!raw",
+			expected:  "/lorem%20ipsum/a%2Fb/d%C3%A9j%C3%A0.html",
+		},
+		{
+			name:      "raw suffix authority",
+			component: "authority!raw",
+			expected:  "us%65r:p%40ss@ex%C3%A4mple.com:8080",
+		},
+		{
+			name:      "raw suffix user",
+			component: "user!raw",
+			expected:  "us%65r",
+		},
+		{
+			name:      "raw suffix password",
+			component: "password!raw",
+			expected:  "p%40ss",
+		},
+		{
+			name:      "raw suffix host",
+			component: "host!raw",
+			expected:  "ex%C3%A4mple.com",
+		},
+		{
+			name:      "raw suffix hostPort",
+			component: "hostPort!raw",
+			expected:  "ex%C3%A4mple.com:8080",
+		},
+		{
+			name:      "raw suffix file",
+			component: "file!raw",
+			expected:  "d%C3%A9j%C3%A0.html",
+		},
+		{
+			name:      "raw suffix segment",
+			component: "segment:1!raw",
+			expected:  "a%2Fb",
+		},
+		{
+			name:      "raw suffix query param",
+			component: "query:name!raw",
+			expected:  "%7Evalue",
+		},
+		{
+			name:      "raw suffix fragment",
+			component: "fragment!raw",
+			expected:  "at=no%20stra&unde=omnis",
+		},
+		{
+			name:      "raw suffix fragment param",
+			component: "fragment:at!raw",
+			expected:  "no%20stra",
+		},
+		{
+			name:      "raw suffix relativeUrl",
+			rawUrl:    "http://example.com/a%2fb?#x%2a",
+			component: "relativeUrl!raw",
+			expected:  "/a%2fb?#x%2a",
+		},
+		{
+			name:      "raw suffix no raw variant",
+			component: "port!raw",
+			expected:  "8080",
+		},
+		{
+			name:      "raw suffix only path",
+			rawUrl:    "/lorem%20ipsum.html",
+			component: "path!raw",
+			expected:  "/lorem%20ipsum.html",
+		},
+		{
+			name:      "raw suffix opaque",
+			rawUrl:    "mailto:user@example.com",
+			component: "host!raw",
+		},
+		{
+			name:      "raw suffix opaque path",
+			rawUrl:    "mailto:user@example.com.html",
+			component: "path!raw",
+		},
+		{
+			name:      "raw suffix opaque file",
+			rawUrl:    "mailto:user@example.com.html",
+			component: "file!raw",
+		},
+		{
+			name:      "raw suffix opaque relativeUrl",
+			rawUrl:    "mailto:user@example.com?subject=a%2Bb#x%2a",
+			component: "relativeUrl!raw",
+			expected:  "?subject=a%2Bb#x%2a",
+		},
+		// ----------- decoded suffix
+		{
+			name:      "decoded suffix query",
+			component: "query!decoded",
+			expected:  "q=a+b c&name=~value",
+		},
+		{
+			name:      "decoded suffix relativeUrl",
+			component: "relativeUrl!decoded",
+			expected:  "/lorem ipsum/a/b/déjà.html?q=a+b+c&name=~value#at=no stra&unde=omnis",
+		},
+		{
+			name:      "decoded suffix authority",
+			component: "authority!decoded",
+			expected:  "user:p@ss@exämple.com:8080",
+		},
+		{
+			name:      "decoded suffix path:x:y",
+			component: "path:1!decoded",
+			expected:  "/a/b/déjà.html",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in path",
+			rawUrl:    "http://example.com/a%2541?q=%2541#f%2541",
+			component: "path!decoded",
+			expected:  "/a%41",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in query param",
+			rawUrl:    "http://example.com/a%2541?q=%2541#f%2541",
+			component: "query:q!decoded",
+			expected:  "%41",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in fragment",
+			rawUrl:    "http://example.com/a%2541?q=%2541#f%2541",
+			component: "fragment!decoded",
+			expected:  "f%41",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in user",
+			rawUrl:    "http://us%2541er@example.com/",
+			component: "user!decoded",
+			expected:  "us%41er",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in file",
+			rawUrl:    "http://example.com/a%2541/b%2541.html",
+			component: "file!decoded",
+			expected:  "b%41.html",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in segment",
+			rawUrl:    "http://example.com/a%2541/b%2541.html",
+			component: "segment:0!decoded",
+			expected:  "a%41",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in query",
+			rawUrl:    "http://example.com/a%2541?q=%2541#f%2541",
+			component: "query!decoded",
+			expected:  "q=%41",
+		},
+		{
+			name:      "decoded suffix encoded percent sign in relativeUrl",
+			rawUrl:    "http://example.com/a%2541?q=%2541#f%2541",
+			component: "relativeUrl!decoded",
+			expected:  "/a%41?q=%41#f%41",
+		},
+		{
+			name:      "decoded encoding encoded percent sign",
+			rawUrl:    "http://example.com/a%2541?q=%2541#f%2541",
+			encoding:  DecodedEncoding,
+			component: "query:q",
+			expected:  "%41",
+		},
+		// ----------- parser encoding
+		{
+			name:      "raw encoding",
+			encoding:  RawEncoding,
+			component: "path",
+			expected:  "/lorem%20ipsum/a%2Fb/d%C3%A9j%C3%A0.html",
+		},
+		{
+			name:      "raw encoding overridden",
+			encoding:  RawEncoding,
+			component: "host!decoded",
+			expected:  "exämple.com",
+		},
+		{
+			name:      "decoded encoding",
+			encoding:  DecodedEncoding,
+			component: "rawPath",
+			expected:  "/lorem ipsum/a/b/déjà.html",
+		},
+		// ----------- normalized input
+		{
+			name:      "whatwg raw host",
+			rawUrl:    `https:\\user:p%61ss@Example.com\a\..\b?q=1`,
+			whatwg:    true,
+			component: "host!raw",
+			expected:  "example.com",
+		},
+		{
+			name:      "whatwg raw authority",
+			rawUrl:    `https:\\user:p%61ss@Example.com\a\..\b?q=1`,
+			whatwg:    true,
+			component: "authority!raw",
+			expected:  "user:p%61ss@example.com",
+		},
+		{
+			name:      "whatwg raw encoding path",
+			rawUrl:    `https:\\user:p%61ss@Example.com\a\..\b?q=1`,
+			encoding:  RawEncoding,
+			whatwg:    true,
+			component: "path",
+			expected:  "/b",
+		},
+		{
+			name:      "whatwg raw encoding relativeUrl",
+			rawUrl:    `https:\\user:p%61ss@Example.com\a\..\b?q=1`,
+			encoding:  RawEncoding,
+			whatwg:    true,
+			component: "relativeUrl",
+			expected:  "/b?q=1",
+		},
+		{
+			name:          "trailing slash removed raw path",
+			rawUrl:        "http://example.com/a%2Fb//?q=1#f",
+			trailingSlash: RemoveTrailingSlash,
+			component:     "path!raw",
+			expected:      "/a%2Fb",
+		},
+		{
+			name:          "trailing slash removed raw relativeUrl",
+			rawUrl:        "http://example.com/a%2Fb//?q=1#f",
+			encoding:      RawEncoding,
+			trailingSlash: RemoveTrailingSlash,
+			component:     "relativeUrl",
+			expected:      "/a%2Fb?q=1#f",
+		},
+		{
+			name:          "trailing slash added raw relativeUrl",
+			rawUrl:        "http://example.com?q=1",
+			encoding:      RawEncoding,
+			trailingSlash: AddTrailingSlash,
+			component:     "relativeUrl",
+			expected:      "/?q=1",
+		},
+		// ----------- exclamation marks in names
+		{
+			name:      "exclamation mark in query name",
+			rawUrl:    "http://example.com/?a!b=1",
+			component: "query:a!b",
+			expected:  "1",
+		},
+		{
+			name:      "exclamation mark in query name with suffix",
+			rawUrl:    "http://example.com/?a!b=%31",
+			component: "query:a!b!raw",
+			expected:  "%31",
+		},
+		{
+			name:      "exclamation mark in fragment name",
+			rawUrl:    "http://example.com/#a!b=1",
+			component: "fragment:a!b",
+			expected:  "1",
+		},
+		{
+			name:      "exclamation mark in matrix name",
+			rawUrl:    "http://example.com/lorem;a!b=1",
+			component: "matrix:0:a!b",
+			expected:  "1",
+		},
+		// ----------- invalid
+		{
+			name:      "invalid suffix",
+			component: "path!lorem",
+			expectErr: true,
+		},
+		{
+			name:      "invalid component",
+			component: "lorem!raw",
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			if test.rawUrl == "" {
+				test.rawUrl = encodedUrl
+			}
+
+			parser := Parser{Encoding: test.encoding, Whatwg: test.whatwg, TrailingSlash: test.trailingSlash}
+			result, err := parser.Component(test.rawUrl, test.component)
+
+			if test.expectErr {
+				require.Error(err)
+			} else {
+				require.NoError(err)
+			}
+
+			require.Equal(test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package urlparser
+
+import (
+	"strings"
+	"sync"
+)
+
+var compoundExts = struct {
+	sync.RWMutex
+	exts map[string]struct{}
+}{
+	exts: map[string]struct{}{
+		"tar.gz":   {},
+		"tar.bz2":  {},
+		"tar.xz":   {},
+		"tar.zst":  {},
+		"tar.lz":   {},
+		"tar.lzma": {},
+		"tar.z":    {},
+	},
+}
+
+// mimeTypes is used instead of mime.TypeByExtension, which also loads the system tables (/etc/mime.types, ...),
+// so that mimeType gives the same results on every machine.
+var mimeTypes = map[string]string{
+	"7z":    "application/x-7z-compressed",
+	"avif":  "image/avif",
+	"bmp":   "image/bmp",
+	"bz2":   "application/x-bzip2",
+	"css":   "text/css; charset=utf-8",
+	"csv":   "text/csv; charset=utf-8",
+	"doc":   "application/msword",
+	"docx":  "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"epub":  "application/epub+zip",
+	"gif":   "image/gif",
+	"gz":    "application/gzip",
+	"htm":   "text/html; charset=utf-8",
+	"html":  "text/html; charset=utf-8",
+	"ico":   "image/vnd.microsoft.icon",
+	"ics":   "text/calendar; charset=utf-8",
+	"jar":   "application/java-archive",
+	"jpeg":  "image/jpeg",
+	"jpg":   "image/jpeg",
+	"js":    "text/javascript; charset=utf-8",
+	"json":  "application/json",
+	"md":    "text/markdown; charset=utf-8",
+	"mjs":   "text/javascript; charset=utf-8",
+	"mp3":   "audio/mpeg",
+	"mp4":   "video/mp4",
+	"mpeg":  "video/mpeg",
+	"oga":   "audio/ogg",
+	"ogg":   "audio/ogg",
+	"ogv":   "video/ogg",
+	"otf":   "font/otf",
+	"pdf":   "application/pdf",
+	"png":   "image/png",
+	"ppt":   "application/vnd.ms-powerpoint",
+	"pptx":  "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"rar":   "application/vnd.rar",
+	"rss":   "application/rss+xml",
+	"rtf":   "application/rtf",
+	"svg":   "image/svg+xml",
+	"tar":   "application/x-tar",
+	"tif":   "image/tiff",
+	"tiff":  "image/tiff",
+	"ttf":   "font/ttf",
+	"txt":   "text/plain; charset=utf-8",
+	"wasm":  "application/wasm",
+	"wav":   "audio/wav",
+	"weba":  "audio/webm",
+	"webm":  "video/webm",
+	"webp":  "image/webp",
+	"woff":  "font/woff",
+	"woff2": "font/woff2",
+	"xhtml": "application/xhtml+xml",
+	"xls":   "application/vnd.ms-excel",
+	"xlsx":  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"xml":   "text/xml; charset=utf-8",
+	"xz":    "application/x-xz",
+	"zip":   "application/zip",
+	"zst":   "application/zstd",
+}
+
+func RegisterCompoundExt(ext string) {
+	compoundExts.Lock()
+	defer compoundExts.Unlock()
+
+	compoundExts.exts[strings.ToLower(strings.TrimPrefix(ext, "."))] = struct{}{}
+}
+
+func isCompoundExt(ext string) bool {
+	compoundExts.RLock()
+	defer compoundExts.RUnlock()
+
+	_, ok := compoundExts.exts[strings.ToLower(ext)]
+
+	return ok
+}
+
+// getExt returns the extension of the file, which is the part after the last dot,
+// unless the file name ends with one of the known compound extensions (tar.gz, ...).
+func getExt(file string) string {
+	lastDot := strings.LastIndex(file, ".")
+	if lastDot == -1 {
+		return ""
+	}
+
+	for dot := strings.LastIndex(file[:lastDot], "."); dot != -1; dot = strings.LastIndex(file[:dot], ".") {
+		if isCompoundExt(file[dot+1:]) {
+			return file[dot+1:]
+		}
+	}
+
+	return file[lastDot+1:]
+}
+
+func getLastExt(file string) string {
+	lastDot := strings.LastIndex(file, ".")
+	if lastDot == -1 {
+		return ""
+	}
+
+	return file[lastDot+1:]
+}
+
+func getFileStem(file string) string {
+	ext := getExt(file)
+	if ext == "" {
+		return file
+	}
+
+	return file[:len(file)-len(ext)-1]
+}
+
+func getMimeType(file string) string {
+	ext := getLastExt(file)
+	if ext == "" {
+		return ""
+	}
+
+	return mimeTypes[strings.ToLower(ext)]
+}
@@ -0,0 +1,163 @@
+package urlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileExtensions(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawUrl   string
+		ext      string
+		lastExt  string
+		fileStem string
+	}{
+		{
+			name:     "ref url",
+			rawUrl:   refUrl,
+			ext:      "html",
+			lastExt:  "html",
+			fileStem: "sit",
+		},
+		{
+			name:   "no file",
+			rawUrl: "http://example.com/lorem/ipsum",
+		},
+		{
+			name:     "multiple dots",
+			rawUrl:   "http://example.com/js/jquery.min.js",
+			ext:      "js",
+			lastExt:  "js",
+			fileStem: "jquery.min",
+		},
+		{
+			name:     "versioned file",
+			rawUrl:   "http://example.com/releases/v1.2.3-release.zip",
+			ext:      "zip",
+			lastExt:  "zip",
+			fileStem: "v1.2.3-release",
+		},
+		{
+			name:     "compound extension",
+			rawUrl:   "http://example.com/releases/archive.tar.gz",
+			ext:      "tar.gz",
+			lastExt:  "gz",
+			fileStem: "archive",
+		},
+		{
+			name:     "versioned compound extension",
+			rawUrl:   "http://example.com/releases/linux-6.1.2.TAR.XZ",
+			ext:      "TAR.XZ",
+			lastExt:  "XZ",
+			fileStem: "linux-6.1.2",
+		},
+		{
+			name:     "hidden file",
+			rawUrl:   "http://example.com/.htaccess",
+			ext:      "htaccess",
+			lastExt:  "htaccess",
+			fileStem: "",
+		},
+		{
+			name:     "upper case extension",
+			rawUrl:   "http://example.com/images/logo.PNG",
+			ext:      "PNG",
+			lastExt:  "PNG",
+			fileStem: "logo",
+		},
+		{
+			name:     "unknown extension",
+			rawUrl:   "http://example.com/lorem.ipsum-dolor",
+			ext:      "ipsum-dolor",
+			lastExt:  "ipsum-dolor",
+			fileStem: "lorem",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			result, err := Format(test.rawUrl, "{ext}|{lastExt}|{fileStem}")
+
+			require.NoError(err)
+			require.Equal(test.ext+"|"+test.lastExt+"|"+test.fileStem, result)
+		})
+	}
+}
+
+func TestMimeType(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawUrl   string
+		expected string
+	}{
+		{
+			name:     "ref url",
+			rawUrl:   refUrl,
+			expected: "text/html; charset=utf-8",
+		},
+		{
+			name:     "multiple dots",
+			rawUrl:   "http://example.com/js/jquery.min.js",
+			expected: "text/javascript; charset=utf-8",
+		},
+		{
+			name:     "upper case extension",
+			rawUrl:   "http://example.com/images/logo.PNG",
+			expected: "image/png",
+		},
+		{
+			name:     "json",
+			rawUrl:   "http://example.com/api/v1/users.json",
+			expected: "application/json",
+		},
+		{
+			name:     "compound extension",
+			rawUrl:   "http://example.com/dist/lorem.tar.gz",
+			expected: "application/gzip",
+		},
+		{
+			name:   "unknown extension",
+			rawUrl: "http://example.com/lorem.ipsum-dolor",
+		},
+		{
+			name:   "extension only in system tables",
+			rawUrl: "http://example.com/lorem.deb",
+		},
+		{
+			name:   "no file",
+			rawUrl: "http://example.com/lorem/ipsum",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			result, err := Component(test.rawUrl, MimeType)
+
+			require.NoError(err)
+			require.Equal(test.expected, result)
+		})
+	}
+}
+
+func TestRegisterCompoundExt(t *testing.T) {
+	require := require.New(t)
+
+	result, err := Component("http://example.com/app.bundle.min.js", Ext)
+	require.NoError(err)
+	require.Equal("js", result)
+
+	RegisterCompoundExt(".Min.JS")
+	defer func() {
+		compoundExts.Lock()
+		delete(compoundExts.exts, "min.js")
+		compoundExts.Unlock()
+	}()
+
+	result, err = Format("http://example.com/app.bundle.min.js", "{ext} {fileStem}")
+	require.NoError(err)
+	require.Equal("min.js app.bundle", result)
+}
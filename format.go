@@ -1,18 +1,23 @@
 package urlparser
 
 import (
-	"net/url"
 	"regexp"
 	"strings"
 )
 
+var placeholderRegexp = regexp.MustCompile(`(?i){[a-z0-9:+!-]+}`)
+
 func Format(rawUrl string, format string) (string, error) {
-	parsedUrl, err := url.Parse(rawUrl)
+	return Parser{}.Format(rawUrl, format)
+}
+
+func (p Parser) Format(rawUrl string, format string) (string, error) {
+	parsedUrl, err := p.parse(rawUrl)
 	if err != nil {
 		return "", err
 	}
 
-	matches := regexp.MustCompile(`(?i){[a-z0-9:+-]+}`).FindAllString(format, -1)
+	matches := placeholderRegexp.FindAllString(format, -1)
 	if matches == nil {
 		return "", nil
 	}
@@ -24,7 +29,7 @@ func Format(rawUrl string, format string) (string, error) {
 
 	replacements := make([]string, 0, len(requiredComponents)*2)
 	for placeholder := range requiredComponents {
-		component, err := getComponent(parsedUrl, placeholder[1:len(placeholder)-1])
+		component, err := p.getEncodedComponent(parsedUrl, placeholder[1:len(placeholder)-1])
 		if err != nil {
 			return "", err
 		}
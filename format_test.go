@@ -55,3 +55,62 @@ func TestFormat(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkFormat(b *testing.B) {
+	formats := []struct {
+		name   string
+		format string
+	}{
+		{
+			name:   "small",
+			format: "{scheme}://{host}",
+		},
+		{
+			name: "large",
+			format: "{scheme}://{authority}{relativeUrl} {hostType} {tld} {host:-0:2} {port} {effectivePort}" +
+				" {path} {path:0:2} {segment:-1} {file} {ext} {mimeType} {query} {query:amet} {fragment:unde}" +
+				" {path!raw} {user!decoded}",
+		},
+	}
+	for _, format := range formats {
+		b.Run(format.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_, _ = Format(refUrl, format.format)
+			}
+		})
+	}
+}
+
+func FuzzFormat(f *testing.F) {
+	f.Add(refUrl)
+	f.Add("mongodb://user:p%40ss@h1:27017,h2:27018/db?replicaSet=rs0")
+	f.Add("http://[fe80::1%25en0]:8080/lorem;a=b/ipsum%2Fdolor?sit=amet%20#unde%3Domnis")
+	f.Add("HTTP://EXAMPLE.com/a b/?c d#e f")
+
+	f.Fuzz(func(t *testing.T, rawUrl string) {
+		result, err := Format(rawUrl, "{scheme}://{authority}{relativeUrl}")
+		if err != nil {
+			return
+		}
+
+		// only absolute URLs can be put back together by the format
+		scheme, _ := Component(rawUrl, Scheme)
+		host, _ := Component(rawUrl, Host)
+		if scheme == "" || host == "" {
+			return
+		}
+
+		// formatting the formatted URL must give the same URL, with the same components
+		again, err := Format(result, "{scheme}://{authority}{relativeUrl}")
+		require.NoError(t, err)
+		require.Equal(t, result, again)
+
+		for _, component := range []string{Scheme, User, Password, Hosts, Path, Query, Fragment} {
+			expected, _ := Component(rawUrl, component)
+			actual, _ := Component(result, component)
+			require.Equal(t, expected, actual, component)
+		}
+	})
+}
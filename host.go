@@ -0,0 +1,161 @@
+package urlparser
+
+import (
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+const (
+	hostTypeEmpty    = "empty"
+	hostTypeDns      = "dns"
+	hostTypeIpv4     = "ipv4"
+	hostTypeIpv6     = "ipv6"
+	hostTypeIpv6Zone = "ipv6-zone"
+)
+
+func parseHostAddr(host string) (netip.Addr, bool) {
+	if host == "" {
+		return netip.Addr{}, false
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return parseWhatwgIpv4(host)
+	}
+
+	return addr, true
+}
+
+// parseWhatwgIpv4 parses IPv4 hosts the way browsers do, see https://url.spec.whatwg.org/#concept-ipv4-parser.
+// Besides the dotted quad it accepts fewer parts (127.1), and decimal, octal (0177) or hex (0x7f) numbers.
+func parseWhatwgIpv4(host string) (netip.Addr, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+
+	numbers := make([]uint64, len(parts))
+	for i, part := range parts {
+		number, ok := parseWhatwgIpv4Number(part)
+		if !ok {
+			return netip.Addr{}, false
+		}
+
+		numbers[i] = number
+	}
+
+	var ipv4 uint64
+	for i, number := range numbers[:len(numbers)-1] {
+		if number > 255 {
+			return netip.Addr{}, false
+		}
+
+		ipv4 += number << (8 * (3 - i))
+	}
+
+	last := numbers[len(numbers)-1]
+	if last >= 1<<(8*(5-len(numbers))) {
+		return netip.Addr{}, false
+	}
+
+	ipv4 += last
+
+	return netip.AddrFrom4([4]byte{byte(ipv4 >> 24), byte(ipv4 >> 16), byte(ipv4 >> 8), byte(ipv4)}), true
+}
+
+func parseWhatwgIpv4Number(part string) (uint64, bool) {
+	if part == "" {
+		return 0, false
+	}
+
+	base := 10
+	if len(part) >= 2 && (part[:2] == "0x" || part[:2] == "0X") {
+		base = 16
+		part = part[2:]
+	} else if len(part) >= 2 && part[0] == '0' {
+		base = 8
+		part = part[1:]
+	}
+
+	if part == "" {
+		return 0, true
+	}
+
+	if part[0] == '+' || part[0] == '-' {
+		return 0, false
+	}
+
+	number, err := strconv.ParseUint(part, base, 64)
+	if err != nil {
+		// numbers too large to fit are still numbers, the address is invalid anyway
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return math.MaxUint64, true
+		}
+
+		return 0, false
+	}
+
+	return number, true
+}
+
+func getCanonicalIpv4(host string) string {
+	addr, ok := parseHostAddr(host)
+	if !ok || !addr.Is4() {
+		return ""
+	}
+
+	return addr.String()
+}
+
+func isObfuscatedIpv4(host string) string {
+	canonical := getCanonicalIpv4(host)
+
+	return strconv.FormatBool(canonical != "" && canonical != host)
+}
+
+func getHostType(host string) string {
+	if host == "" {
+		return hostTypeEmpty
+	}
+
+	addr, ok := parseHostAddr(host)
+	switch {
+	case !ok:
+		return hostTypeDns
+	case addr.Is4():
+		return hostTypeIpv4
+	case addr.Zone() != "":
+		return hostTypeIpv6Zone
+	default:
+		return hostTypeIpv6
+	}
+}
+
+func getIpVersion(host string) string {
+	addr, ok := parseHostAddr(host)
+	if !ok {
+		return ""
+	}
+
+	if addr.Is4() {
+		return "4"
+	}
+
+	return "6"
+}
+
+func getIpFlag(host string, flag func(addr netip.Addr) bool) string {
+	addr, ok := parseHostAddr(host)
+	if !ok {
+		return strconv.FormatBool(false)
+	}
+
+	// classify IPv4-mapped addresses (::ffff:127.0.0.1) as their IPv4 counterparts
+	return strconv.FormatBool(flag(addr.Unmap()))
+}
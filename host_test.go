@@ -0,0 +1,344 @@
+package urlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		rawUrl      string
+		hostType    string
+		ipVersion   string
+		isLoopback  string
+		isPrivate   string
+		isLinkLocal string
+		isMulticast string
+	}{
+		{
+			name:        "ref url",
+			rawUrl:      refUrl,
+			hostType:    "dns",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "only path",
+			rawUrl:      "/lorem/ipsum.html",
+			hostType:    "empty",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "public ipv4",
+			rawUrl:      "http://93.184.216.34:8080/",
+			hostType:    "ipv4",
+			ipVersion:   "4",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "loopback ipv4",
+			rawUrl:      "http://127.0.0.1/",
+			hostType:    "ipv4",
+			ipVersion:   "4",
+			isLoopback:  "true",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "private ipv4",
+			rawUrl:      "http://user@192.168.1.1/",
+			hostType:    "ipv4",
+			ipVersion:   "4",
+			isLoopback:  "false",
+			isPrivate:   "true",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "link-local ipv4",
+			rawUrl:      "http://169.254.169.254/latest/meta-data/",
+			hostType:    "ipv4",
+			ipVersion:   "4",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "true",
+			isMulticast: "false",
+		},
+		{
+			name:        "multicast ipv4",
+			rawUrl:      "udp://239.255.255.250:1900",
+			hostType:    "ipv4",
+			ipVersion:   "4",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "true",
+		},
+		{
+			name:        "decimal loopback ipv4",
+			rawUrl:      "http://2130706433/",
+			hostType:    "ipv4",
+			ipVersion:   "4",
+			isLoopback:  "true",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "hex private ipv4",
+			rawUrl:      "http://0xc0.0xa8.0x1.0x1/",
+			hostType:    "ipv4",
+			ipVersion:   "4",
+			isLoopback:  "false",
+			isPrivate:   "true",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "public ipv6",
+			rawUrl:      "http://[2001:db8::1428:57ab]/",
+			hostType:    "ipv6",
+			ipVersion:   "6",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "loopback ipv6",
+			rawUrl:      "http://[::1]:8080/",
+			hostType:    "ipv6",
+			ipVersion:   "6",
+			isLoopback:  "true",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "ipv4-mapped loopback ipv6",
+			rawUrl:      "http://[::ffff:127.0.0.1]/",
+			hostType:    "ipv6",
+			ipVersion:   "6",
+			isLoopback:  "true",
+			isPrivate:   "false",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "unique local ipv6",
+			rawUrl:      "http://[fd12:3456:789a::1]/",
+			hostType:    "ipv6",
+			ipVersion:   "6",
+			isLoopback:  "false",
+			isPrivate:   "true",
+			isLinkLocal: "false",
+			isMulticast: "false",
+		},
+		{
+			name:        "link-local ipv6 with zone",
+			rawUrl:      "http://[fe80::1%25en0]/",
+			hostType:    "ipv6-zone",
+			ipVersion:   "6",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "true",
+			isMulticast: "false",
+		},
+		{
+			name:        "multicast ipv6",
+			rawUrl:      "http://[ff02::1]/",
+			hostType:    "ipv6",
+			ipVersion:   "6",
+			isLoopback:  "false",
+			isPrivate:   "false",
+			isLinkLocal: "true",
+			isMulticast: "true",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			result, err := Format(
+				test.rawUrl,
+				"{hostType}|{ipVersion}|{isLoopback}|{isPrivate}|{isLinkLocal}|{isMulticast}",
+			)
+
+			require.NoError(err)
+			require.Equal(
+				test.hostType+"|"+test.ipVersion+"|"+test.isLoopback+"|"+test.isPrivate+"|"+
+					test.isLinkLocal+"|"+test.isMulticast,
+				result,
+			)
+		})
+	}
+}
+
+func TestIpv4(t *testing.T) {
+	tests := []struct {
+		name       string
+		rawUrl     string
+		whatwg     bool
+		ipv4       string
+		obfuscated string
+	}{
+		{
+			name:       "ref url",
+			rawUrl:     refUrl,
+			obfuscated: "false",
+		},
+		{
+			name:       "only path",
+			rawUrl:     "/lorem/ipsum.html",
+			obfuscated: "false",
+		},
+		{
+			name:       "dotted quad",
+			rawUrl:     "http://127.0.0.1/",
+			ipv4:       "127.0.0.1",
+			obfuscated: "false",
+		},
+		{
+			name:       "decimal",
+			rawUrl:     "http://2130706433/",
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "hex",
+			rawUrl:     "http://0x7f000001/",
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "hex parts",
+			rawUrl:     "http://0x7f.1/",
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "octal",
+			rawUrl:     "http://0177.0.0.1/",
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "octal is not decimal",
+			rawUrl:     "http://010.0.0.010/",
+			ipv4:       "8.0.0.8",
+			obfuscated: "true",
+		},
+		{
+			name:       "three parts",
+			rawUrl:     "http://10.1.258/",
+			ipv4:       "10.1.1.2",
+			obfuscated: "true",
+		},
+		{
+			name:       "trailing dot",
+			rawUrl:     "http://127.0.0.1./",
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "zero",
+			rawUrl:     "http://0/",
+			ipv4:       "0.0.0.0",
+			obfuscated: "true",
+		},
+		{
+			name:       "uppercase hex with port",
+			rawUrl:     "http://0X7F.0X0.0X0.0X1:8080/",
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "out of range",
+			rawUrl:     "http://256.0.0.1/",
+			obfuscated: "false",
+		},
+		{
+			name:       "last part out of range",
+			rawUrl:     "http://127.16777216/",
+			obfuscated: "false",
+		},
+		{
+			name:       "too large",
+			rawUrl:     "http://99999999999999999999999/",
+			obfuscated: "false",
+		},
+		{
+			name:       "too many parts",
+			rawUrl:     "http://1.2.3.4.5/",
+			obfuscated: "false",
+		},
+		{
+			name:       "invalid octal",
+			rawUrl:     "http://09.0.0.1/",
+			obfuscated: "false",
+		},
+		{
+			name:       "signed",
+			rawUrl:     "http://+1.0.0.1/",
+			obfuscated: "false",
+		},
+		{
+			name:       "domain",
+			rawUrl:     "http://0x7f.example.com/",
+			obfuscated: "false",
+		},
+		{
+			name:       "ipv6",
+			rawUrl:     "http://[::ffff:7f00:1]/",
+			obfuscated: "false",
+		},
+		{
+			name:       "whatwg hex parts",
+			rawUrl:     "http://0x7f.1/",
+			whatwg:     true,
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "whatwg percent-encoded decimal with backslashes",
+			rawUrl:     `http:\\user@%32130706433:8080\lorem`,
+			whatwg:     true,
+			ipv4:       "127.0.0.1",
+			obfuscated: "true",
+		},
+		{
+			name:       "whatwg dotted quad",
+			rawUrl:     "http://127.0.0.1/",
+			whatwg:     true,
+			ipv4:       "127.0.0.1",
+			obfuscated: "false",
+		},
+		{
+			name:       "whatwg domain",
+			rawUrl:     "http://Example.com/",
+			whatwg:     true,
+			obfuscated: "false",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			result, err := Parser{Whatwg: test.whatwg}.Format(test.rawUrl, "{ipv4}|{isObfuscatedIpv4}")
+
+			require.NoError(err)
+			require.Equal(test.ipv4+"|"+test.obfuscated, result)
+		})
+	}
+}
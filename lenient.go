@@ -0,0 +1,138 @@
+package urlparser
+
+import (
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+var (
+	lenientSchemeSlashes = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):[/\\]*`)
+	lenientPort          = regexp.MustCompile(`^:[0-9]*$`)
+)
+
+// authoritySchemes are the schemes whose URLs always have an authority, so that the slashes after them may be fixed.
+// Other schemes may be opaque (sip:alice@example.com), even those with a default port.
+var authoritySchemes = map[string]bool{
+	"http":  true,
+	"https": true,
+	"ftp":   true,
+	"ws":    true,
+	"wss":   true,
+}
+
+func ParseLenient(rawUrl string, assumeScheme string) (*url.URL, error) {
+	parsedUrl, err := url.Parse(lenientUrl(rawUrl, assumeScheme))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = rawUrl
+		}
+
+		return nil, err
+	}
+
+	return parsedUrl, nil
+}
+
+// lenientUrl cleans up URLs pasted from emails, chats, logs etc. It trims whitespace, quotes and angle brackets,
+// fixes the slashes after web schemes (http:/example.com) and adds the scheme to host-first input (example.com/x).
+func lenientUrl(rawUrl string, assumeScheme string) string {
+	rawUrl = trimLenient(rawUrl)
+
+	if match := lenientSchemeSlashes.FindStringSubmatch(rawUrl); match != nil {
+		if authoritySchemes[strings.ToLower(match[1])] {
+			return match[1] + "://" + rawUrl[len(match[0]):]
+		}
+	}
+
+	if strings.HasPrefix(rawUrl, "//") {
+		return withAssumedScheme(rawUrl, assumeScheme)
+	}
+
+	if isHostFirst(rawUrl) {
+		return withAssumedScheme("//"+rawUrl, assumeScheme)
+	}
+
+	return rawUrl
+}
+
+func trimLenient(rawUrl string) string {
+	for {
+		trimmed := strings.TrimSpace(rawUrl)
+		if len(trimmed) >= 2 {
+			switch trimmed[0:1] + trimmed[len(trimmed)-1:] {
+			case `""`, "''", "``", "<>", "()":
+				trimmed = trimmed[1 : len(trimmed)-1]
+			case "[]":
+				// keep the brackets of bare IPv6 hosts ([::1])
+				if addr, err := netip.ParseAddr(trimmed[1 : len(trimmed)-1]); err != nil || !addr.Is6() {
+					trimmed = trimmed[1 : len(trimmed)-1]
+				}
+			}
+		}
+
+		if trimmed == rawUrl {
+			return rawUrl
+		}
+
+		rawUrl = trimmed
+	}
+}
+
+func withAssumedScheme(rawUrl string, assumeScheme string) string {
+	if assumeScheme == "" {
+		return rawUrl
+	}
+
+	return assumeScheme + ":" + rawUrl
+}
+
+// isHostFirst checks whether the URL starts with something that looks like a host, e.g. www.example.com:8080/x.
+// To avoid mistaking relative paths (ipsum.html) for hosts, only IP addresses, localhost
+// and domains with a public suffix managed by ICANN are recognized.
+func isHostFirst(rawUrl string) bool {
+	authority := rawUrl
+	if i := strings.IndexAny(authority, "/\\?#"); i != -1 {
+		authority = authority[:i]
+	}
+
+	host := authority
+	if strings.HasPrefix(host, "[") {
+		end := strings.Index(host, "]")
+		if end == -1 || !lenientPort.MatchString(host[end+1:]) && host[end+1:] != "" {
+			return false
+		}
+
+		addr, err := netip.ParseAddr(host[1:end])
+
+		return err == nil && addr.Is6()
+	}
+
+	if i := strings.Index(host, ":"); i != -1 {
+		if !lenientPort.MatchString(host[i:]) {
+			return false
+		}
+
+		host = host[:i]
+	}
+
+	if host == "" || strings.Contains(host, "@") {
+		return false
+	}
+
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && addr.Is4() {
+		return true
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	suffix, icann := publicsuffix.PublicSuffix(host)
+
+	return icann && suffix != host
+}
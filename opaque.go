@@ -0,0 +1,97 @@
+package urlparser
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// getMailtoRecipients lists the decoded addresses of mailto URLs, both before the "?" and in the "to" headers (RFC 6068).
+func getMailtoRecipients(parsedUrl *url.URL) string {
+	if parsedUrl.Scheme != "mailto" {
+		return ""
+	}
+
+	rawAddresses := []string{parsedUrl.Opaque}
+	for _, header := range getMailtoHeaderPairs(parsedUrl.RawQuery) {
+		if header[0] == "to" {
+			rawAddresses = append(rawAddresses, header[1])
+		}
+	}
+
+	var recipients []string
+	for _, addresses := range rawAddresses {
+		addresses, err := url.PathUnescape(addresses)
+		if err != nil {
+			continue
+		}
+
+		for _, address := range strings.Split(addresses, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				recipients = append(recipients, address)
+			}
+		}
+	}
+
+	return strings.Join(recipients, ",")
+}
+
+// getMailtoHeaders returns the header fields of mailto URLs other than "to", sorted by name. They are kept
+// as they appear in the URL, since unlike in query strings, "+" is a literal character in them.
+func getMailtoHeaders(parsedUrl *url.URL) string {
+	if parsedUrl.Scheme != "mailto" {
+		return ""
+	}
+
+	var headers []string
+	for _, header := range getMailtoHeaderPairs(parsedUrl.RawQuery) {
+		if header[0] != "to" {
+			headers = append(headers, header[2])
+		}
+	}
+
+	sort.SliceStable(headers, func(i, j int) bool {
+		return mailtoHeaderName(headers[i]) < mailtoHeaderName(headers[j])
+	})
+
+	return strings.Join(headers, "&")
+}
+
+// getMailtoHeaderPairs splits the headers into the decoded lowercase name, raw value and the raw name=value pair.
+func getMailtoHeaderPairs(rawQuery string) [][3]string {
+	var headers [][3]string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		_, value, _ := strings.Cut(pair, "=")
+		headers = append(headers, [3]string{mailtoHeaderName(pair), value, pair})
+	}
+
+	return headers
+}
+
+func mailtoHeaderName(pair string) string {
+	name, _, _ := strings.Cut(pair, "=")
+	if decoded, err := url.PathUnescape(name); err == nil {
+		name = decoded
+	}
+
+	return strings.ToLower(name)
+}
+
+// getUrnParts splits urn:<NID>:<NSS> (RFC 8141) into the namespace identifier, lowercased as it's case-insensitive,
+// and the namespace specific string, kept percent-encoded like opaque since the encoding is part of its identity.
+func getUrnParts(parsedUrl *url.URL) (string, string) {
+	if parsedUrl.Scheme != "urn" {
+		return "", ""
+	}
+
+	namespace, nss, ok := strings.Cut(parsedUrl.Opaque, ":")
+	if !ok {
+		return "", ""
+	}
+
+	return strings.ToLower(namespace), nss
+}
@@ -0,0 +1,248 @@
+package urlparser
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Parser holds the parsing options. Zero Parser parses URLs according to RFC 3986, as url.Parse does.
+// Parser and all the functions of this package are safe for concurrent use, including RegisterDefaultPort
+// and RegisterCompoundExt, which may be called while other goroutines parse URLs.
+type Parser struct {
+	// Whatwg makes the parser follow the WHATWG URL Standard (as browsers do) instead of RFC 3986.
+	Whatwg bool
+	// Lenient makes the parser accept sloppy input, see ParseLenient.
+	Lenient bool
+	// AssumeScheme is the scheme used for scheme-less input in the lenient mode.
+	AssumeScheme string
+	// TrailingSlashDirs treats only paths with a trailing slash as directories, so the file component
+	// of /docs/README is README, even though it has no extension.
+	TrailingSlashDirs bool
+	// TrailingSlash adds or removes the trailing slash of paths before their components are extracted.
+	TrailingSlash TrailingSlash
+	// Encoding is used for components without an encoding suffix (path!raw, path!decoded).
+	Encoding Encoding
+}
+
+type TrailingSlash int
+
+const (
+	KeepTrailingSlash TrailingSlash = iota
+	AddTrailingSlash
+	RemoveTrailingSlash
+)
+
+type urlParts struct {
+	*url.URL
+
+	hosts []string
+	// inputHost is the host as it appeared in the input, if the parser normalized it (see Parser.Whatwg)
+	inputHost string
+
+	rawAuthority   string
+	rawUserinfo    string
+	rawHosts       string
+	rawPath        string
+	rawFragment    string
+	rawRelativeUrl string
+}
+
+func (p Parser) parse(rawUrl string) (*urlParts, error) {
+	input := rawUrl
+	if p.Lenient {
+		input = lenientUrl(rawUrl, p.AssumeScheme)
+	}
+
+	var parsedUrl *urlParts
+	var err error
+	if p.Whatwg {
+		// the raw parts follow the URL as the WHATWG parser normalized it, not the input
+		parsedUrl, input, err = parseWhatwgParts(input)
+	} else {
+		parsedUrl, err = parseUrl(input)
+	}
+
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = rawUrl
+		}
+
+		return nil, err
+	}
+
+	setRawParts(parsedUrl, input)
+
+	if p.TrailingSlash != KeepTrailingSlash {
+		normalizeTrailingSlash(parsedUrl, p.TrailingSlash)
+	}
+
+	return parsedUrl, nil
+}
+
+// normalizeTrailingSlash changes both the path and its raw variant, so that they stay consistent.
+func normalizeTrailingSlash(parsedUrl *urlParts, mode TrailingSlash) {
+	if parsedUrl.Opaque != "" {
+		return
+	}
+
+	escapedPath, ok := withTrailingSlash(parsedUrl.EscapedPath(), parsedUrl.Host != "", mode)
+	if !ok {
+		return
+	}
+
+	parsedUrl.Path, _ = url.PathUnescape(escapedPath)
+	parsedUrl.RawPath = escapedPath
+
+	if rawPath, ok := withTrailingSlash(parsedUrl.rawPath, parsedUrl.Host != "", mode); ok {
+		parsedUrl.rawRelativeUrl = rawPath + parsedUrl.rawRelativeUrl[len(parsedUrl.rawPath):]
+		parsedUrl.rawPath = rawPath
+	}
+}
+
+func withTrailingSlash(escapedPath string, hasHost bool, mode TrailingSlash) (string, bool) {
+	switch {
+	case mode == AddTrailingSlash && !strings.HasSuffix(escapedPath, "/"):
+		if escapedPath == "" && !hasHost {
+			return "", false
+		}
+
+		return escapedPath + "/", true
+	case mode == RemoveTrailingSlash && len(escapedPath) > 1 && strings.HasSuffix(escapedPath, "/"):
+		escapedPath = strings.TrimRight(escapedPath, "/")
+		if escapedPath == "" {
+			escapedPath = "/"
+		}
+
+		return escapedPath, true
+	default:
+		return "", false
+	}
+}
+
+func parseWhatwgParts(rawUrl string) (*urlParts, string, error) {
+	parsedUrl, href, inputHost, err := parseWhatwg(rawUrl)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var hosts []string
+	if parsedUrl.Host != "" {
+		hosts = []string{parsedUrl.Host}
+	}
+
+	return &urlParts{URL: parsedUrl, hosts: hosts, inputHost: inputHost}, href, nil
+}
+
+func parseUrl(rawUrl string) (*urlParts, error) {
+	hosts, rawSingleHostUrl := splitHosts(rawUrl)
+
+	parsedUrl, err := url.Parse(rawSingleHostUrl)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			// report the error against the input, not the rewritten URL
+			urlErr.URL = rawUrl
+		}
+
+		return nil, err
+	}
+
+	if hosts == nil && parsedUrl.Host != "" {
+		hosts = []string{parsedUrl.Host}
+	}
+
+	// decode the other hosts the same way as the first one is
+	for i := 1; i < len(hosts); i++ {
+		hostUrl, err := url.Parse("//" + hosts[i])
+		if err != nil {
+			return nil, &url.Error{Op: "parse", URL: rawUrl, Err: errors.New("invalid host " + strconv.Quote(hosts[i]))}
+		}
+
+		hosts[i] = hostUrl.Host
+	}
+
+	if len(hosts) > 1 {
+		hosts[0] = parsedUrl.Host
+	}
+
+	return &urlParts{URL: parsedUrl, hosts: hosts}, nil
+}
+
+// multiHostSchemes are the schemes of connection strings which may list several hosts. Elsewhere,
+// commas are valid characters of the host (RFC 3986), so http://h1,h2.example.com has a single host.
+var multiHostSchemes = map[string]bool{
+	"mongodb":     true,
+	"mongodb+srv": true,
+	"postgres":    true,
+	"postgresql":  true,
+	"mysql":       true,
+	"mariadb":     true,
+	"redis":       true,
+	"rediss":      true,
+	"amqp":        true,
+	"amqps":       true,
+	"cassandra":   true,
+	"couchbase":   true,
+	"couchbases":  true,
+	"memcached":   true,
+	"nats":        true,
+}
+
+// splitHosts detects multi-host authorities (e.g. mongodb://h1:27017,h2:27017/db), which url.Parse
+// can't handle, and returns all the hosts along with the URL rewritten to contain only the first one.
+func splitHosts(rawUrl string) ([]string, string) {
+	schemeEnd := strings.Index(rawUrl, "://")
+	if schemeEnd < 1 || !multiHostSchemes[strings.ToLower(rawUrl[:schemeEnd])] {
+		return nil, rawUrl
+	}
+
+	authorityStart, authorityEnd, ok := findAuthority(rawUrl)
+	if !ok {
+		return nil, rawUrl
+	}
+
+	hostStart := authorityStart + strings.LastIndex(rawUrl[authorityStart:authorityEnd], "@") + 1
+	if !strings.Contains(rawUrl[hostStart:authorityEnd], ",") {
+		return nil, rawUrl
+	}
+
+	rawHosts := strings.Split(rawUrl[hostStart:authorityEnd], ",")
+
+	return rawHosts, rawUrl[:hostStart] + rawHosts[0] + rawUrl[authorityEnd:]
+}
+
+// getRawUserinfo returns the userinfo exactly as it appears in the raw URL.
+func getRawUserinfo(rawUrl string) (string, bool) {
+	authorityStart, authorityEnd, ok := findAuthority(rawUrl)
+	if !ok {
+		return "", false
+	}
+
+	userinfoEnd := strings.LastIndex(rawUrl[authorityStart:authorityEnd], "@")
+	if userinfoEnd == -1 {
+		return "", false
+	}
+
+	return rawUrl[authorityStart : authorityStart+userinfoEnd], true
+}
+
+// findAuthority returns the position of the authority within the raw URL, if it has any.
+func findAuthority(rawUrl string) (int, int, bool) {
+	authorityStart := 2
+	if !strings.HasPrefix(rawUrl, "//") {
+		schemeEnd := strings.Index(rawUrl, "://")
+		if schemeEnd < 1 || strings.ContainsAny(rawUrl[:schemeEnd], "/?#") {
+			return 0, 0, false
+		}
+
+		authorityStart += schemeEnd + 1
+	}
+
+	authorityEnd := strings.IndexAny(rawUrl[authorityStart:], "/?#")
+	if authorityEnd == -1 {
+		return authorityStart, len(rawUrl), true
+	}
+
+	return authorityStart, authorityStart + authorityEnd, true
+}
@@ -0,0 +1,55 @@
+package urlparser
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentUse(t *testing.T) {
+	rules, err := ParseRules([]byte(testRules))
+	require.NoError(t, err)
+
+	parsers := []Parser{
+		{},
+		{Whatwg: true},
+		{Lenient: true, AssumeScheme: "https"},
+		{TrailingSlash: AddTrailingSlash, Encoding: RawEncoding},
+	}
+
+	run := func(p Parser) []string {
+		component, _ := p.Component(refUrl, Host)
+		format, _ := p.Format(refUrl, "{scheme}://{host:-0:2}{path}{effectivePort}")
+		with, _ := p.With(refUrl, PathSegment(-1), "amet.php")
+		rewritten, _ := p.ApplyRules("http://blog.example.com/2023/lorem?utm_source=x", rules)
+		redacted, _ := Redact(refUrl, RedactOptions{Params: RedactedParams})
+
+		return []string{component, format, with, rewritten, redacted}
+	}
+
+	expected := make([][]string, len(parsers))
+	for i, p := range parsers {
+		expected[i] = run(p)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				p := (i + j) % len(parsers)
+				// require would call t.FailNow outside of the test goroutine
+				assert.Equal(t, expected[p], run(parsers[p]))
+
+				RegisterDefaultPort("concurrent", 1234)
+				RegisterCompoundExt("concurrent.gz")
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
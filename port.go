@@ -0,0 +1,97 @@
+package urlparser
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var defaultPorts = struct {
+	sync.RWMutex
+	ports map[string]int
+}{
+	ports: map[string]int{
+		"ftp":        21,
+		"ssh":        22,
+		"sftp":       22,
+		"telnet":     23,
+		"smtp":       25,
+		"dns":        53,
+		"gopher":     70,
+		"http":       80,
+		"ws":         80,
+		"pop3":       110,
+		"nntp":       119,
+		"imap":       143,
+		"snmp":       161,
+		"ldap":       389,
+		"https":      443,
+		"wss":        443,
+		"smtps":      465,
+		"rtsp":       554,
+		"ipp":        631,
+		"ldaps":      636,
+		"ftps":       990,
+		"imaps":      993,
+		"pop3s":      995,
+		"mssql":      1433,
+		"sqlserver":  1433,
+		"oracle":     1521,
+		"mqtt":       1883,
+		"nfs":        2049,
+		"mysql":      3306,
+		"mariadb":    3306,
+		"rdp":        3389,
+		"svn":        3690,
+		"sip":        5060,
+		"sips":       5061,
+		"xmpp":       5222,
+		"postgres":   5432,
+		"postgresql": 5432,
+		"amqp":       5672,
+		"amqps":      5671,
+		"vnc":        5900,
+		"redis":      6379,
+		"rediss":     6379,
+		"irc":        6667,
+		"ircs":       6697,
+		"git":        9418,
+		"memcached":  11211,
+		"mongodb":    27017,
+	},
+}
+
+func RegisterDefaultPort(scheme string, port int) {
+	defaultPorts.Lock()
+	defer defaultPorts.Unlock()
+
+	defaultPorts.ports[strings.ToLower(scheme)] = port
+}
+
+func DefaultPort(scheme string) (int, bool) {
+	defaultPorts.RLock()
+	defer defaultPorts.RUnlock()
+
+	port, ok := defaultPorts.ports[strings.ToLower(scheme)]
+
+	return port, ok
+}
+
+func getEffectivePort(parsedUrl *url.URL) string {
+	port := parsedUrl.Port()
+	if port != "" {
+		return port
+	}
+
+	if parsedUrl.Host == "" {
+		return ""
+	}
+
+	defaultPort, ok := DefaultPort(parsedUrl.Scheme)
+	if !ok {
+		return ""
+	}
+
+	return strconv.Itoa(defaultPort)
+}
@@ -0,0 +1,76 @@
+package urlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		scheme   string
+		expected int
+		known    bool
+	}{
+		{
+			name:     "http",
+			scheme:   "http",
+			expected: 80,
+			known:    true,
+		},
+		{
+			name:     "upper case scheme",
+			scheme:   "HTTPS",
+			expected: 443,
+			known:    true,
+		},
+		{
+			name:     "redis",
+			scheme:   "redis",
+			expected: 6379,
+			known:    true,
+		},
+		{
+			name:   "unknown",
+			scheme: "lorem",
+		},
+		{
+			name:   "empty",
+			scheme: "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			port, ok := DefaultPort(test.scheme)
+
+			require.Equal(test.known, ok)
+			require.Equal(test.expected, port)
+		})
+	}
+}
+
+func TestRegisterDefaultPort(t *testing.T) {
+	require := require.New(t)
+
+	result, err := Component("ipsum://example.co.uk", EffectivePort)
+	require.NoError(err)
+	require.Equal("", result)
+
+	RegisterDefaultPort("Ipsum", 4321)
+	defer func() {
+		defaultPorts.Lock()
+		delete(defaultPorts.ports, "ipsum")
+		defaultPorts.Unlock()
+	}()
+
+	result, err = Component("ipsum://example.co.uk", EffectivePort)
+	require.NoError(err)
+	require.Equal("4321", result)
+
+	result, err = Component("ipsum://example.co.uk", HostPortEffective)
+	require.NoError(err)
+	require.Equal("example.co.uk:4321", result)
+}
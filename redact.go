@@ -0,0 +1,119 @@
+package urlparser
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+const redactedMask = "xxxxx"
+
+var RedactedParams = []string{"*token*", "*key*", "*secret*", "sig", "signature", "*password*"}
+
+type RedactOptions struct {
+	// User masks the whole userinfo instead of just the password.
+	User bool
+	// Params are case-insensitive glob patterns (see path.Match) of query and fragment parameter names to mask.
+	Params []string
+	// Mask replaces the redacted values, defaults to "xxxxx".
+	Mask string
+}
+
+func Redact(rawUrl string, opts RedactOptions) (string, error) {
+	_, err := parseUrl(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	for _, pattern := range opts.Params {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return "", err
+		}
+	}
+
+	if opts.Mask == "" {
+		opts.Mask = redactedMask
+	}
+
+	fragmentStart := strings.Index(rawUrl, "#")
+	if fragmentStart == -1 {
+		fragmentStart = len(rawUrl)
+	}
+
+	queryStart := strings.Index(rawUrl[:fragmentStart], "?")
+	if queryStart == -1 {
+		queryStart = fragmentStart
+	}
+
+	// fragments of OAuth redirects carry the tokens as parameters too (#access_token=...),
+	// in single-page apps after the route (#/callback?access_token=...)
+	fragment := rawUrl[fragmentStart:]
+	fragmentParamsStart := strings.LastIndex(fragment, "?")
+	if fragmentParamsStart == -1 {
+		fragmentParamsStart = 0
+	}
+
+	return redactUserinfo(rawUrl[:queryStart], opts) +
+		redactParams(rawUrl[queryStart:fragmentStart], opts) +
+		fragment[:fragmentParamsStart] + redactParams(fragment[fragmentParamsStart:], opts), nil
+}
+
+func redactUserinfo(rawUrl string, opts RedactOptions) string {
+	userinfo, ok := getRawUserinfo(rawUrl)
+	if !ok {
+		return rawUrl
+	}
+
+	userinfoStart, _, _ := findAuthority(rawUrl)
+	userinfoEnd := userinfoStart + len(userinfo)
+	if !opts.User {
+		passwordStart := strings.Index(userinfo, ":")
+		if passwordStart == -1 {
+			return rawUrl
+		}
+
+		userinfoStart += passwordStart + 1
+	}
+
+	return rawUrl[:userinfoStart] + opts.Mask + rawUrl[userinfoEnd:]
+}
+
+// redactParams masks the matching parameters of the query or the fragment, given with its leading "?" or "#".
+func redactParams(rawParams string, opts RedactOptions) string {
+	if rawParams == "" || len(opts.Params) == 0 {
+		return rawParams
+	}
+
+	pairs := strings.Split(rawParams[1:], "&")
+	for i, pair := range pairs {
+		nameEnd := strings.Index(pair, "=")
+		if nameEnd == -1 {
+			continue
+		}
+
+		name, err := url.QueryUnescape(pair[:nameEnd])
+		if err != nil {
+			name = pair[:nameEnd]
+		}
+
+		if matchesAny(strings.ToLower(name), opts.Params) {
+			pairs[i] = pair[:nameEnd+1] + opts.Mask
+		}
+	}
+
+	return rawParams[:1] + strings.Join(pairs, "&")
+}
+
+// matchesAny matches the name like path.Match, except that * matches slashes too, as names aren't paths.
+func matchesAny(name string, patterns []string) bool {
+	// slashes are replaced by NUL, which path.Match doesn't treat specially
+	name = strings.ReplaceAll(name, "/", "\x00")
+	for _, pattern := range patterns {
+		pattern = strings.ReplaceAll(strings.ToLower(pattern), "/", "\x00")
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,265 @@
+package urlparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules are applied to each URL in order. Rule matches when all its conditions match the URL
+// as rewritten by the preceding rules. Rules may be shared by goroutines as long as they aren't modified.
+//
+//	rules:
+//	  - name: blog migration
+//	    match:
+//	      - component: host
+//	        equals: blog.example.com
+//	      - component: path
+//	        regex: ^/\d{4}/
+//	    actions:
+//	      - set: host
+//	        value: www.example.com
+//	      - replace: path
+//	        regex: ^/(\d{4})/(.*)$
+//	        with: /blog/$1/$2
+//	      - remove: query:utm_source
+//	    last: true
+type Rules []Rule
+
+type Rule struct {
+	Name    string      `yaml:"name"`
+	Match   []Condition `yaml:"match"`
+	Actions []Action    `yaml:"actions"`
+	// Last stops processing of the following rules when this one matches.
+	Last bool `yaml:"last"`
+
+	compiled bool
+}
+
+// Condition checks the component with exactly one of Equals, Prefix, Suffix and Regex.
+type Condition struct {
+	Component string  `yaml:"component"`
+	Equals    *string `yaml:"equals"`
+	Prefix    *string `yaml:"prefix"`
+	Suffix    *string `yaml:"suffix"`
+	Regex     *string `yaml:"regex"`
+	// Not negates the condition.
+	Not bool `yaml:"not"`
+
+	regex *regexp.Regexp
+}
+
+// Action is exactly one of:
+//   - Set: the component is replaced with Value, which may contain {COMPONENT} placeholders (see Format)
+//   - Remove: the component is removed
+//   - Replace: matches of Regex in the component are replaced with With, which may contain $1-style references
+type Action struct {
+	Set     string `yaml:"set"`
+	Value   string `yaml:"value"`
+	Remove  string `yaml:"remove"`
+	Replace string `yaml:"replace"`
+	Regex   string `yaml:"regex"`
+	With    string `yaml:"with"`
+
+	regex *regexp.Regexp
+}
+
+type rulesFile struct {
+	Rules Rules `yaml:"rules"`
+}
+
+func ParseRules(data []byte) (Rules, error) {
+	var file rulesFile
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(&file)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range file.Rules {
+		err = file.Rules[i].compile()
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", file.Rules[i].describe(i), err)
+		}
+	}
+
+	return file.Rules, nil
+}
+
+func (r *Rule) describe(index int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("#%d (%s)", index+1, r.Name)
+	}
+
+	return fmt.Sprintf("#%d", index+1)
+}
+
+func (r *Rule) compile() error {
+	// the conditions and actions are copied, so that compiling doesn't modify the rules shared by the callers
+	r.Match = append([]Condition(nil), r.Match...)
+	r.Actions = append([]Action(nil), r.Actions...)
+	r.compiled = true
+
+	if len(r.Actions) == 0 {
+		return errors.New("no actions")
+	}
+
+	for i := range r.Match {
+		condition := &r.Match[i]
+		if condition.Component == "" {
+			return errors.New("condition without component")
+		}
+
+		operators := 0
+		for _, operator := range []*string{condition.Equals, condition.Prefix, condition.Suffix, condition.Regex} {
+			if operator != nil {
+				operators++
+			}
+		}
+
+		if operators != 1 {
+			return fmt.Errorf("condition on %s must have exactly one of equals, prefix, suffix and regex", condition.Component)
+		}
+
+		if condition.Regex != nil {
+			regex, err := regexp.Compile(*condition.Regex)
+			if err != nil {
+				return err
+			}
+
+			condition.regex = regex
+		}
+	}
+
+	for i := range r.Actions {
+		action := &r.Actions[i]
+
+		actions := 0
+		for _, component := range []string{action.Set, action.Remove, action.Replace} {
+			if component != "" {
+				actions++
+			}
+		}
+
+		if actions != 1 {
+			return errors.New("action must have exactly one of set, remove and replace")
+		}
+
+		if action.Replace != "" {
+			regex, err := regexp.Compile(action.Regex)
+			if err != nil {
+				return err
+			}
+
+			action.regex = regex
+		}
+	}
+
+	return nil
+}
+
+func ApplyRules(rawUrl string, rules Rules) (string, error) {
+	return Parser{}.ApplyRules(rawUrl, rules)
+}
+
+// ApplyRules rewrites the URL according to the rules. Rules built in Go, rather than by ParseRules,
+// are validated and compiled on each call.
+func (p Parser) ApplyRules(rawUrl string, rules Rules) (string, error) {
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.compiled {
+			compiled := *rule
+			if err := compiled.compile(); err != nil {
+				return "", fmt.Errorf("rule %s: %w", rule.describe(i), err)
+			}
+
+			rule = &compiled
+		}
+
+		matches, err := p.matchRule(rawUrl, rule)
+		if err != nil {
+			return "", fmt.Errorf("rule %s: %w", rule.describe(i), err)
+		}
+
+		if !matches {
+			continue
+		}
+
+		for _, action := range rule.Actions {
+			rawUrl, err = p.applyAction(rawUrl, action)
+			if err != nil {
+				return "", fmt.Errorf("rule %s: %w", rule.describe(i), err)
+			}
+		}
+
+		if rule.Last {
+			break
+		}
+	}
+
+	return rawUrl, nil
+}
+
+func (p Parser) matchRule(rawUrl string, rule *Rule) (bool, error) {
+	for _, condition := range rule.Match {
+		component, err := p.Component(rawUrl, condition.Component)
+		if err != nil {
+			return false, err
+		}
+
+		var matches bool
+		switch {
+		case condition.Equals != nil:
+			matches = component == *condition.Equals
+		case condition.Prefix != nil:
+			matches = strings.HasPrefix(component, *condition.Prefix)
+		case condition.Suffix != nil:
+			matches = strings.HasSuffix(component, *condition.Suffix)
+		case condition.regex != nil:
+			matches = condition.regex.MatchString(component)
+		}
+
+		if matches == condition.Not {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (p Parser) applyAction(rawUrl string, action Action) (string, error) {
+	switch {
+	case action.Set != "":
+		value := action.Value
+		if placeholderRegexp.MatchString(value) {
+			var err error
+			value, err = p.Format(rawUrl, value)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		return p.With(rawUrl, action.Set, value)
+	case action.Remove != "":
+		return p.With(rawUrl, action.Remove, "")
+	default:
+		component, err := p.Component(rawUrl, action.Replace)
+		if err != nil {
+			return "", err
+		}
+
+		return p.With(rawUrl, action.Replace, action.regex.ReplaceAllString(component, action.With))
+	}
+}
@@ -0,0 +1,187 @@
+package urlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+rules:
+  - name: blog migration
+    match:
+      - component: host
+        equals: blog.example.com
+      - component: path
+        regex: ^/\d{4}/
+    actions:
+      - set: host
+        value: www.example.com
+      - replace: path
+        regex: ^/(\d{4})/(.*)$
+        with: /blog/$1/$2
+      - remove: query:utm_source
+    last: true
+  - name: force https
+    match:
+      - component: scheme
+        equals: http
+    actions:
+      - set: scheme
+        value: https
+  - name: legacy docs
+    match:
+      - component: path
+        prefix: /docs/
+      - component: ext
+        equals: php
+        not: true
+    actions:
+      - set: relativeUrl
+        value: /documentation{path:1}
+  - actions:
+      - remove: fragment
+`
+
+func TestApplyRules(t *testing.T) {
+	rules, err := ParseRules([]byte(testRules))
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+
+	tests := []struct {
+		name     string
+		rawUrl   string
+		expected string
+	}{
+		{
+			name:     "first rule stops processing",
+			rawUrl:   "http://blog.example.com/2023/hello-world?utm_source=x&page=2#top",
+			expected: "http://www.example.com/blog/2023/hello-world?page=2#top",
+		},
+		{
+			name:     "first rule doesn't match",
+			rawUrl:   "http://blog.example.com/about#top",
+			expected: "https://blog.example.com/about",
+		},
+		{
+			name:     "placeholders",
+			rawUrl:   "https://example.com/docs/guide/install?lang=en",
+			expected: "https://example.com/documentation/guide/install",
+		},
+		{
+			name:     "negated condition",
+			rawUrl:   "https://example.com/docs/index.php",
+			expected: "https://example.com/docs/index.php",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			result, err := ApplyRules(test.rawUrl, rules)
+			require.NoError(err)
+			require.Equal(test.expected, result)
+		})
+	}
+}
+
+func TestApplyRulesErrors(t *testing.T) {
+	require := require.New(t)
+
+	rules, err := ParseRules([]byte("rules:\n  - name: invalid\n    match:\n      - component: lorem\n        equals: x\n    actions:\n      - remove: query\n"))
+	require.NoError(err)
+
+	_, err = ApplyRules("http://example.com/", rules)
+	require.EqualError(err, "rule #1 (invalid): invalid component: lorem")
+
+	_, err = ApplyRules("http://[::1", rules)
+	require.Error(err)
+}
+
+func TestApplyRulesBuiltInGo(t *testing.T) {
+	require := require.New(t)
+
+	pattern, replacement := `^/(\d{4})/`, "/blog/$1/"
+	rules := Rules{
+		{
+			Match:   []Condition{{Component: "path", Regex: &pattern}},
+			Actions: []Action{{Replace: "path", Regex: pattern, With: replacement}},
+		},
+	}
+
+	result, err := ApplyRules("http://example.com/2023/lorem", rules)
+	require.NoError(err)
+	require.Equal("http://example.com/blog/2023/lorem", result)
+	require.Nil(rules[0].Match[0].regex)
+
+	invalid := "("
+	_, err = ApplyRules("http://example.com/", Rules{{Match: []Condition{{Component: "path", Regex: &invalid}}, Actions: rules[0].Actions}})
+	require.ErrorContains(err, "rule #1: ")
+
+	_, err = ApplyRules("http://example.com/", Rules{{Name: "empty"}})
+	require.EqualError(err, "rule #1 (empty): no actions")
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       string
+		expectedErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:        "unknown field",
+			rules:       "rules:\n  - lorem: ipsum\n",
+			expectedErr: "yaml: unmarshal errors:\n  line 2: field lorem not found in type urlparser.Rule",
+		},
+		{
+			name:        "no actions",
+			rules:       "rules:\n  - name: lorem\n",
+			expectedErr: "rule #1 (lorem): no actions",
+		},
+		{
+			name:        "condition without component",
+			rules:       "rules:\n  - match:\n      - equals: x\n    actions:\n      - remove: query\n",
+			expectedErr: "rule #1: condition without component",
+		},
+		{
+			name:        "condition without operator",
+			rules:       "rules:\n  - match:\n      - component: host\n    actions:\n      - remove: query\n",
+			expectedErr: "rule #1: condition on host must have exactly one of equals, prefix, suffix and regex",
+		},
+		{
+			name:        "condition with multiple operators",
+			rules:       "rules:\n  - match:\n      - component: host\n        equals: x\n        prefix: x\n    actions:\n      - remove: query\n",
+			expectedErr: "rule #1: condition on host must have exactly one of equals, prefix, suffix and regex",
+		},
+		{
+			name:        "invalid condition regex",
+			rules:       "rules:\n  - match:\n      - component: host\n        regex: (\n    actions:\n      - remove: query\n",
+			expectedErr: "rule #1: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:        "action with multiple types",
+			rules:       "rules:\n  - actions:\n      - remove: query\n        set: path\n",
+			expectedErr: "rule #1: action must have exactly one of set, remove and replace",
+		},
+		{
+			name:        "invalid action regex",
+			rules:       "rules:\n  - actions:\n      - replace: path\n        regex: (\n",
+			expectedErr: "rule #1: error parsing regexp: missing closing ): `(`",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			_, err := ParseRules([]byte(test.rules))
+			if test.expectedErr == "" {
+				require.NoError(err)
+			} else {
+				require.EqualError(err, test.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package urlparser
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// plainChars are the characters which url.Parse keeps as they are in every part of the URL.
+var plainChars = func() (chars [256]bool) {
+	for _, c := range "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-._~!$&'()*+,;=:@/?" {
+		chars[c] = true
+	}
+
+	return chars
+}()
+
+// scannedUrl holds the parts of a plain URL, sliced directly from the raw string.
+type scannedUrl struct {
+	scheme   string
+	hostPort string
+	host     string
+	port     string
+	path     string
+	query    string
+	fragment string
+}
+
+func AppendComponent(dst []byte, rawUrl string, component string) ([]byte, error) {
+	return Parser{}.AppendComponent(dst, rawUrl, component)
+}
+
+// AppendComponent appends the component to dst. For the most common components of plain URLs it doesn't allocate.
+func (p Parser) AppendComponent(dst []byte, rawUrl string, component string) ([]byte, error) {
+	result, err := p.Component(rawUrl, component)
+	if err != nil {
+		return dst, err
+	}
+
+	return append(dst, result...), nil
+}
+
+// getScannedComponent extracts the component without parsing the whole URL, if both are simple enough:
+// the URL must be a plain absolute URL (scheme://host:port/path?query#fragment, ASCII only,
+// no user info, no percent-encoding) and the component one of the common ones. Otherwise, it returns false
+// and the URL must be parsed the usual way, which also reports any errors.
+func (p Parser) getScannedComponent(rawUrl string, component string) (string, bool) {
+	if p.Whatwg || p.Lenient || p.TrailingSlash != KeepTrailingSlash || p.Encoding == DecodedEncoding {
+		return "", false
+	}
+
+	scanned, ok := scanUrl(rawUrl)
+	if !ok {
+		return "", false
+	}
+
+	switch true {
+	case component == Scheme:
+		for i := 0; i < len(scanned.scheme); i++ {
+			if 'A' <= scanned.scheme[i] && scanned.scheme[i] <= 'Z' {
+				return strings.ToLower(scanned.scheme), true
+			}
+		}
+
+		return scanned.scheme, true
+	case component == HostPort:
+		return scanned.hostPort, true
+	case component == Host:
+		return scanned.host, true
+	case component == Port:
+		return scanned.port, true
+	case component == Path, component == RawPath:
+		return scanned.path, true
+	case component == Query:
+		return scanned.query, true
+	case component == Fragment:
+		return scanned.fragment, true
+	case component == Tld:
+		if !isDomain(scanned.host) {
+			return "", false
+		}
+
+		tld, _ := publicsuffix.PublicSuffix(scanned.host)
+
+		return tld, true
+	case strings.HasPrefix(component, boundedHostPrefix):
+		return scanHostLabels(scanned.host, component)
+	case strings.HasPrefix(component, boundedHostPortPrefix):
+		if scanned.port != "" {
+			return "", false
+		}
+
+		return scanHostLabels(scanned.host, component)
+	case strings.HasPrefix(component, boundedPathPrefix):
+		if scanned.path == "" || strings.Contains(scanned.path, ";") {
+			return "", false
+		}
+
+		start, end, err := getBoundsWithin(component, strings.Count(scanned.path, "/"))
+		if err != nil {
+			return "", false
+		}
+
+		if start == end {
+			return "", true
+		}
+
+		// the segments are counted in the path without the leading slash, which is then kept in the result
+		from, to := getPartsBounds(scanned.path[1:], '/', start, end)
+
+		return scanned.path[from : to+1], true
+	case strings.HasPrefix(component, pathSegmentPrefix):
+		if scanned.path == "" || strings.Contains(scanned.path, ";") ||
+			component == pathSegmentPrefix || strings.Count(component, ":") != 1 {
+			return "", false
+		}
+
+		// without the count, the bounds span from the segment to the end (or from the start, for -x)
+		start, end, err := getBoundsWithin(component, strings.Count(scanned.path, "/"))
+		if err != nil {
+			return "", false
+		}
+
+		if start == end {
+			return "", true
+		}
+
+		if component[len(pathSegmentPrefix)] == '-' {
+			start = end - 1
+		}
+
+		from, to := getPartsBounds(scanned.path[1:], '/', start, start+1)
+
+		return scanned.path[from+1 : to+1], true
+	}
+
+	return "", false
+}
+
+func scanUrl(rawUrl string) (scannedUrl, bool) {
+	var scanned scannedUrl
+
+	schemeEnd := strings.Index(rawUrl, "://")
+	if schemeEnd < 1 || !isScheme(rawUrl[:schemeEnd]) {
+		return scanned, false
+	}
+
+	scanned.scheme = rawUrl[:schemeEnd]
+
+	rest := rawUrl[schemeEnd+3:]
+	fragments := 0
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == '#' {
+			fragments++
+		} else if !plainChars[rest[i]] {
+			return scanned, false
+		}
+	}
+
+	if fragments > 1 {
+		return scanned, false
+	}
+
+	if i := strings.IndexByte(rest, '#'); i != -1 {
+		scanned.fragment = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	if i := strings.IndexByte(rest, '?'); i != -1 {
+		scanned.query = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	scanned.hostPort = rest
+	if i := strings.IndexByte(rest, '/'); i != -1 {
+		scanned.hostPort = rest[:i]
+		scanned.path = rest[i:]
+	}
+
+	scanned.host = scanned.hostPort
+	if i := strings.IndexByte(scanned.hostPort, ':'); i != -1 {
+		scanned.host = scanned.hostPort[:i]
+		scanned.port = scanned.hostPort[i+1:]
+	}
+
+	if scanned.host == "" {
+		return scanned, false
+	}
+
+	for i := 0; i < len(scanned.host); i++ {
+		c := scanned.host[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.') {
+			return scanned, false
+		}
+	}
+
+	for i := 0; i < len(scanned.port); i++ {
+		if scanned.port[i] < '0' || scanned.port[i] > '9' {
+			return scanned, false
+		}
+	}
+
+	return scanned, true
+}
+
+func isScheme(scheme string) bool {
+	for i := 0; i < len(scheme); i++ {
+		c := scheme[i]
+		switch {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' || c == '+' || c == '-' || c == '.':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDomain checks that the plain host can't be an IPv4 address, not even an obfuscated one (see parseWhatwgIpv4).
+func isDomain(host string) bool {
+	if strings.HasSuffix(host, ".") {
+		return false
+	}
+
+	label := host[strings.LastIndexByte(host, '.')+1:]
+	if strings.HasPrefix(label, "0x") || strings.HasPrefix(label, "0X") {
+		return false
+	}
+
+	for i := 0; i < len(label); i++ {
+		if label[i] < '0' || label[i] > '9' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanHostLabels is host:x:y (see splitHost) done by slicing the host instead of splitting and joining it.
+func scanHostLabels(host string, component string) (string, bool) {
+	prefix := host
+	tld, _ := publicsuffix.PublicSuffix(host)
+	if tld != "" && tld != host {
+		prefix = host[:len(host)-len(tld)-1]
+	} else {
+		tld = ""
+	}
+
+	labels := strings.Count(prefix, ".") + 1
+	if tld != "" {
+		labels++
+	}
+
+	start, end, err := getBoundsWithin(component, labels)
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	case start == end:
+		return "", true
+	case tld == "" || end < labels:
+		from, to := getPartsBounds(prefix, '.', start, end)
+
+		return prefix[from:to], true
+	case start == labels-1:
+		return tld, true
+	default:
+		from, _ := getPartsBounds(prefix, '.', start, labels-1)
+
+		return host[from:], true
+	}
+}
+
+// getPartsBounds returns the position of the parts from start to end (exclusive) within s, as if s was split
+// by the separator. The parts must not be empty (start < end).
+func getPartsBounds(s string, separator byte, start int, end int) (int, int) {
+	from, part := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != separator {
+			continue
+		}
+
+		part++
+		if part == start {
+			from = i + 1
+		}
+
+		if part == end {
+			return from, i
+		}
+	}
+
+	return from, len(s)
+}
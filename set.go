@@ -0,0 +1,345 @@
+package urlparser
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+func With(rawUrl string, component string, value string) (string, error) {
+	return Parser{}.With(rawUrl, component, value)
+}
+
+// With replaces the component of the URL with the given value and returns the rewritten URL.
+// The value is expected in the same encoding as the component is returned in by Component.
+func (p Parser) With(rawUrl string, component string, value string) (string, error) {
+	parsedUrl, err := p.parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	err = p.setComponent(parsedUrl, component, value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(parsedUrl.hosts) > 1 {
+		parsedUrl.Host = strings.Join(parsedUrl.hosts, ",")
+	}
+
+	result := parsedUrl.String()
+
+	// the values aren't validated by url.URL, so the rewritten URL could be one which can't be parsed again
+	_, err = parseUrl(result)
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+func (p Parser) setComponent(parsedUrl *urlParts, component string, value string) error {
+	switch true {
+	case component == Scheme:
+		if value != "" && !isScheme(value) {
+			return errors.New("invalid scheme: " + value)
+		}
+
+		parsedUrl.Scheme = value
+	case component == Authority:
+		if value == "" {
+			parsedUrl.User = nil
+			setHostPort(parsedUrl, "")
+
+			break
+		}
+
+		authority, err := parseAuthority(parsedUrl.Scheme, value)
+		if err != nil {
+			return err
+		}
+
+		parsedUrl.User = authority.User
+		parsedUrl.Host = authority.Host
+		parsedUrl.hosts = authority.hosts
+	case component == Auth:
+		if value == "" {
+			parsedUrl.User = nil
+
+			break
+		}
+
+		auth, err := parseUrl("//" + value + "@")
+		if err != nil {
+			return err
+		}
+
+		parsedUrl.User = auth.User
+	case component == User:
+		password, hasPassword := parsedUrl.User.Password()
+		switch {
+		case hasPassword:
+			parsedUrl.User = url.UserPassword(value, password)
+		case value != "":
+			parsedUrl.User = url.User(value)
+		default:
+			parsedUrl.User = nil
+		}
+	case component == Password:
+		if parsedUrl.User == nil && value == "" {
+			break
+		}
+
+		if value == "" {
+			parsedUrl.User = url.User(parsedUrl.User.Username())
+		} else {
+			parsedUrl.User = url.UserPassword(parsedUrl.User.Username(), value)
+		}
+	case component == Opaque:
+		parsedUrl.Opaque = value
+	case component == Hosts:
+		if value == "" {
+			parsedUrl.Host = ""
+			parsedUrl.hosts = nil
+
+			break
+		}
+
+		hosts, err := parseAuthority(parsedUrl.Scheme, value)
+		if err != nil {
+			return err
+		}
+
+		parsedUrl.Host = hosts.Host
+		parsedUrl.hosts = hosts.hosts
+	case component == HostPort:
+		setHostPort(parsedUrl, value)
+	case component == Host:
+		setHostPort(parsedUrl, joinHostPort(value, parsedUrl.Port()))
+	case component == Port, component == EffectivePort:
+		if _, err := strconv.ParseUint(value, 10, 16); value != "" && err != nil {
+			return errors.New("invalid port: " + value)
+		}
+
+		setHostPort(parsedUrl, joinHostPort(parsedUrl.Hostname(), value))
+	case component == Tld:
+		host := parsedUrl.Hostname()
+		tld, _ := publicsuffix.PublicSuffix(host)
+		if tld == "" || tld == host {
+			return newInvalidComponentErr(component)
+		}
+
+		setHostPort(parsedUrl, joinHostPort(host[:len(host)-len(tld)]+value, parsedUrl.Port()))
+	case component == Path:
+		setEscapedPath(parsedUrl.URL, (&url.URL{Path: value}).EscapedPath())
+	case component == RawPath:
+		setEscapedPath(parsedUrl.URL, value)
+	case component == BasePath:
+		// the file is kept escaped, so that encoded slashes (a%2Fb.html) don't become separators
+		var escapedFile string
+		if file, _ := p.getComponent(parsedUrl, File); file != "" {
+			escapedPath := parsedUrl.EscapedPath()
+			escapedFile = escapedPath[strings.LastIndex(escapedPath, "/")+1:]
+		}
+
+		if !strings.HasSuffix(value, "/") {
+			value += "/"
+		}
+
+		setEscapedPath(parsedUrl.URL, (&url.URL{Path: value}).EscapedPath()+escapedFile)
+	case component == File:
+		escapedPath := parsedUrl.EscapedPath()
+		file, _ := p.getComponent(parsedUrl, File)
+		if file != "" {
+			escapedPath = escapedPath[:strings.LastIndex(escapedPath, "/")+1]
+		} else if !strings.HasSuffix(escapedPath, "/") {
+			escapedPath += "/"
+		}
+
+		setEscapedPath(parsedUrl.URL, escapedPath+url.PathEscape(value))
+	case component == Ext:
+		file, _ := p.getComponent(parsedUrl, File)
+		if file == "" {
+			return newInvalidComponentErr(component)
+		}
+
+		stem := getFileStem(file)
+		if value != "" {
+			stem += "." + value
+		}
+
+		return p.setComponent(parsedUrl, File, stem)
+	case component == Database:
+		return p.setComponent(parsedUrl, PathSegment(0), value)
+	case component == Query:
+		parsedUrl.RawQuery = value
+		parsedUrl.ForceQuery = false
+	case component == Fragment:
+		parsedUrl.Fragment = value
+		parsedUrl.RawFragment = ""
+	case component == RelativeUrl:
+		relativeUrl, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+
+		parsedUrl.Path = relativeUrl.Path
+		parsedUrl.RawPath = relativeUrl.RawPath
+		parsedUrl.RawQuery = relativeUrl.RawQuery
+		parsedUrl.ForceQuery = relativeUrl.ForceQuery
+		parsedUrl.Fragment = relativeUrl.Fragment
+		parsedUrl.RawFragment = relativeUrl.RawFragment
+	case strings.HasPrefix(component, boundedHostPrefix), strings.HasPrefix(component, boundedHostPortPrefix):
+		labels := splitHost(parsedUrl.Hostname())
+		if parsedUrl.Hostname() == "" {
+			labels = nil
+		}
+
+		start, end, err := getBoundsWithin(component, len(labels))
+		if err != nil {
+			return err
+		}
+
+		labels = replaceWithin(labels, start, end, value, ".")
+		setHostPort(parsedUrl, joinHostPort(strings.Join(labels, "."), parsedUrl.Port()))
+	case strings.HasPrefix(component, boundedPathPrefix):
+		segments := getPathSegments(parsedUrl.URL)
+
+		start, end, err := getBoundsWithin(component, len(segments))
+		if err != nil {
+			return err
+		}
+
+		segments = replaceWithin(segments, start, end, strings.TrimPrefix(value, "/"), "/")
+		if len(segments) == 0 {
+			setEscapedPath(parsedUrl.URL, "")
+		} else {
+			setEscapedPath(parsedUrl.URL, "/"+strings.Join(segments, "/"))
+		}
+	case strings.HasPrefix(component, pathSegmentPrefix):
+		if component == pathSegmentPrefix || strings.Count(component, ":") != 1 {
+			return newInvalidComponentErr(component)
+		}
+
+		segments := getPathSegments(parsedUrl.URL)
+
+		start, end, err := getBoundsWithin(component+":1", len(segments))
+		if err != nil {
+			return newInvalidComponentErr(component)
+		}
+
+		// the segment right after the last one is appended (as Build does), the others must exist
+		switch {
+		case start != end:
+			segments[start] = url.PathEscape(value)
+		case component[len(pathSegmentPrefix):] == strconv.Itoa(len(segments)):
+			segments = append(segments, url.PathEscape(value))
+		default:
+			return errors.New("path segment out of range: " + component)
+		}
+
+		setEscapedPath(parsedUrl.URL, "/"+strings.Join(segments, "/"))
+	case strings.HasPrefix(component, singleQueryPrefix):
+		parsedUrl.RawQuery = setRawParam(parsedUrl.RawQuery, component[len(singleQueryPrefix):], value)
+	case strings.HasPrefix(component, singleFragmentPrefix):
+		fragment := setRawParam(parsedUrl.EscapedFragment(), component[len(singleFragmentPrefix):], value)
+		parsedUrl.Fragment, _ = url.PathUnescape(fragment)
+		parsedUrl.RawFragment = fragment
+	default:
+		return newInvalidComponentErr(component)
+	}
+
+	return nil
+}
+
+// parseAuthority parses the authority in the context of the scheme, which decides whether it may list several hosts.
+func parseAuthority(scheme string, authority string) (*urlParts, error) {
+	if scheme == "" {
+		return parseUrl("//" + authority)
+	}
+
+	return parseUrl(scheme + "://" + authority)
+}
+
+func setHostPort(parsedUrl *urlParts, hostPort string) {
+	parsedUrl.Host = hostPort
+
+	switch {
+	case hostPort == "" && len(parsedUrl.hosts) > 1:
+		parsedUrl.hosts = parsedUrl.hosts[1:]
+		parsedUrl.Host = parsedUrl.hosts[0]
+	case hostPort == "":
+		parsedUrl.hosts = nil
+	case len(parsedUrl.hosts) == 0:
+		parsedUrl.hosts = []string{hostPort}
+	default:
+		parsedUrl.hosts[0] = hostPort
+	}
+}
+
+func joinHostPort(host string, port string) string {
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	if port == "" {
+		return host
+	}
+
+	return host + ":" + port
+}
+
+func setEscapedPath(parsedUrl *url.URL, escapedPath string) {
+	if parsedUrl.Host != "" && escapedPath != "" && !strings.HasPrefix(escapedPath, "/") {
+		escapedPath = "/" + escapedPath
+	}
+
+	parsedUrl.Path = unescapePathSegment(escapedPath)
+	parsedUrl.RawPath = escapedPath
+}
+
+// replaceWithin replaces the parts between start and end with the parts of the value. Empty value removes them.
+func replaceWithin(parts []string, start int, end int, value string, separator string) []string {
+	replaced := append([]string{}, parts[:start]...)
+	if value != "" {
+		replaced = append(replaced, strings.Split(value, separator)...)
+	}
+
+	return append(replaced, parts[end:]...)
+}
+
+// setRawParam sets the value of the first parameter with the given name, removing any other with the same name.
+// Empty value removes the parameter. Other parameters are kept as they are, in their original order.
+func setRawParam(rawParams string, name string, value string) string {
+	var params []string
+	if rawParams != "" {
+		params = strings.Split(rawParams, "&")
+	}
+
+	replaced := false
+	result := make([]string, 0, len(params)+1)
+	for _, param := range params {
+		rawName, _, _ := strings.Cut(param, "=")
+
+		decodedName, err := url.QueryUnescape(rawName)
+		if err != nil {
+			decodedName = rawName
+		}
+
+		if decodedName != name {
+			result = append(result, param)
+		} else if !replaced && value != "" {
+			result = append(result, rawName+"="+url.QueryEscape(value))
+			replaced = true
+		}
+	}
+
+	if !replaced && value != "" {
+		result = append(result, url.QueryEscape(name)+"="+url.QueryEscape(value))
+	}
+
+	return strings.Join(result, "&")
+}
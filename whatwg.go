@@ -0,0 +1,437 @@
+package urlparser
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// https://url.spec.whatwg.org/#special-scheme
+var whatwgSpecialSchemes = map[string]string{
+	"ftp":   "21",
+	"file":  "",
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+var whatwgIdna = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.Transitional(false),
+	idna.StrictDomainName(false),
+	idna.CheckHyphens(false),
+)
+
+const (
+	// https://url.spec.whatwg.org/#c0-control-percent-encode-set is implied for all of the sets below
+	whatwgFragmentSet = " \"<>`"
+	whatwgQuerySet    = " \"#<>"
+	whatwgSpecialSet  = whatwgQuerySet + "'"
+	whatwgPathSet     = whatwgQuerySet + "?`{}"
+	whatwgUserinfoSet = whatwgPathSet + "/:;=@[\\]^|"
+
+	whatwgForbiddenHost   = "\x00\t\n\r #/:<>?@[\\]^|"
+	whatwgForbiddenDomain = whatwgForbiddenHost + "%\x7f"
+)
+
+// parseWhatwg parses absolute URLs the way browsers do, see https://url.spec.whatwg.org/#url-parsing.
+// The input is first serialized as the URL Standard would serialize it, and only then it's parsed by url.Parse,
+// so that all the components are computed from what a browser would see.
+// parseWhatwg returns the parsed URL along with its serialization (href), which it was parsed from,
+// and the host as it appeared in the input.
+func parseWhatwg(rawUrl string) (*url.URL, string, string, error) {
+	href, inputHost, err := whatwgHref(rawUrl)
+	if err != nil {
+		return nil, "", "", &url.Error{Op: "parse", URL: rawUrl, Err: err}
+	}
+
+	parsedUrl, err := url.Parse(href)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = rawUrl
+		}
+
+		return nil, "", "", err
+	}
+
+	return parsedUrl, href, inputHost, nil
+}
+
+// whatwgHref serializes the URL the way browsers do. It also returns the host as it appeared in the input,
+// before it was normalized (e.g. 0x7f.1 instead of 127.0.0.1).
+func whatwgHref(input string) (string, string, error) {
+	input = strings.TrimFunc(input, func(r rune) bool {
+		return r <= ' '
+	})
+	input = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(input)
+
+	schemeEnd := whatwgSchemeEnd(input)
+	if schemeEnd == -1 {
+		return "", "", errors.New("missing scheme")
+	}
+
+	scheme := strings.ToLower(input[:schemeEnd])
+	rest := input[schemeEnd+1:]
+
+	fragment, hasFragment := "", false
+	if i := strings.Index(rest, "#"); i != -1 {
+		fragment, hasFragment = rest[i+1:], true
+		rest = rest[:i]
+	}
+
+	defaultPort, special := whatwgSpecialSchemes[scheme]
+	if special {
+		rest = whatwgSpecialSlashes(rest)
+	}
+
+	query, hasQuery := "", false
+	if i := strings.Index(rest, "?"); i != -1 {
+		query, hasQuery = rest[i+1:], true
+		rest = rest[:i]
+	}
+
+	var href strings.Builder
+	var inputHost string
+	href.WriteString(scheme + ":")
+
+	switch {
+	case scheme == "file" && !strings.HasPrefix(rest, "//"):
+		href.WriteString("//" + whatwgPath("/"+strings.TrimPrefix(rest, "/")))
+	case special || strings.HasPrefix(rest, "//"):
+		authority := strings.TrimLeft(rest, "/")
+		if !special || scheme == "file" {
+			authority = rest[2:]
+		}
+
+		path := ""
+		if i := strings.Index(authority, "/"); i != -1 {
+			authority, path = authority[:i], authority[i:]
+		}
+
+		serializedAuthority, err := whatwgAuthority(authority, scheme, special, defaultPort)
+		if err != nil {
+			return "", "", err
+		}
+
+		inputHost = getWhatwgInputHost(authority)
+
+		if path == "" && special {
+			path = "/"
+		}
+
+		href.WriteString("//" + serializedAuthority + whatwgPath(path))
+	case strings.HasPrefix(rest, "/"):
+		href.WriteString(whatwgPath(rest))
+	default:
+		// opaque path
+		href.WriteString(whatwgPercentEncode(rest, ""))
+	}
+
+	if hasQuery {
+		querySet := whatwgQuerySet
+		if special {
+			querySet = whatwgSpecialSet
+		}
+
+		href.WriteString("?" + whatwgPercentEncode(query, querySet))
+	}
+
+	if hasFragment {
+		href.WriteString("#" + whatwgPercentEncode(fragment, whatwgFragmentSet))
+	}
+
+	return href.String(), inputHost, nil
+}
+
+func whatwgSchemeEnd(input string) int {
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		case i > 0 && c == ':':
+			return i
+		default:
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// whatwgSpecialSlashes treats backslashes as slashes everywhere before the query, as browsers do for special schemes.
+func whatwgSpecialSlashes(rest string) string {
+	queryStart := strings.Index(rest, "?")
+	if queryStart == -1 {
+		queryStart = len(rest)
+	}
+
+	return strings.ReplaceAll(rest[:queryStart], "\\", "/") + rest[queryStart:]
+}
+
+func whatwgAuthority(authority, scheme string, special bool, defaultPort string) (string, error) {
+	var userinfo string
+	if i := strings.LastIndex(authority, "@"); i != -1 {
+		userinfo, authority = authority[:i], authority[i+1:]
+
+		username, password, hasPassword := strings.Cut(userinfo, ":")
+		userinfo = whatwgPercentEncode(username, whatwgUserinfoSet)
+		if hasPassword && password != "" {
+			userinfo += ":" + whatwgPercentEncode(password, whatwgUserinfoSet)
+		}
+
+		if userinfo != "" {
+			userinfo += "@"
+		}
+	}
+
+	host, port := authority, ""
+	if strings.HasPrefix(authority, "[") {
+		if i := strings.Index(authority, "]"); i != -1 {
+			host, port = authority[:i+1], authority[i+1:]
+		}
+	} else if i := strings.Index(authority, ":"); i != -1 {
+		host, port = authority[:i], authority[i:]
+	}
+
+	if port != "" {
+		if port[0] != ':' {
+			return "", errors.New("invalid port " + strconv.Quote(port))
+		}
+
+		port = port[1:]
+		if port != "" {
+			number, err := strconv.ParseUint(port, 10, 16)
+			if err != nil {
+				return "", errors.New("invalid port " + strconv.Quote(port))
+			}
+
+			port = strconv.FormatUint(number, 10)
+		}
+	}
+
+	if port == defaultPort {
+		port = ""
+	}
+
+	host, err := whatwgHost(host, special)
+	if err != nil {
+		return "", err
+	}
+
+	if host == "" && (userinfo != "" || port != "") {
+		return "", errors.New("credentials or port without host")
+	}
+
+	if host == "" && special && scheme != "file" {
+		return "", errors.New("empty host")
+	}
+
+	if scheme == "file" && host == "localhost" {
+		host = ""
+	}
+
+	if port != "" {
+		port = ":" + port
+	}
+
+	return userinfo + host + port, nil
+}
+
+// getWhatwgInputHost returns the decoded host of the authority, which whatwgAuthority has already validated.
+func getWhatwgInputHost(authority string) string {
+	host := authority[strings.LastIndex(authority, "@")+1:]
+	if !strings.HasPrefix(host, "[") {
+		host, _, _ = strings.Cut(host, ":")
+	}
+
+	if decoded, err := url.PathUnescape(host); err == nil {
+		return decoded
+	}
+
+	return host
+}
+
+// https://url.spec.whatwg.org/#host-parsing
+func whatwgHost(host string, special bool) (string, error) {
+	if strings.HasPrefix(host, "[") {
+		if !strings.HasSuffix(host, "]") {
+			return "", errors.New("unterminated ipv6 address")
+		}
+
+		addr, err := netip.ParseAddr(host[1 : len(host)-1])
+		if err != nil || !addr.Is6() || addr.Zone() != "" {
+			return "", errors.New("invalid ipv6 address " + strconv.Quote(host))
+		}
+
+		return "[" + whatwgIpv6(addr) + "]", nil
+	}
+
+	if !special {
+		if strings.ContainsAny(host, whatwgForbiddenHost) {
+			return "", errors.New("invalid host " + strconv.Quote(host))
+		}
+
+		return whatwgPercentEncode(host, ""), nil
+	}
+
+	domain, err := url.PathUnescape(host)
+	if err != nil {
+		domain = host
+	}
+
+	asciiDomain, err := whatwgIdna.ToASCII(domain)
+	if err != nil || asciiDomain == "" && host != "" {
+		return "", errors.New("invalid host " + strconv.Quote(host))
+	}
+
+	if strings.ContainsAny(asciiDomain, whatwgForbiddenDomain) || strings.IndexFunc(asciiDomain, func(r rune) bool {
+		return r < ' '
+	}) != -1 {
+		return "", errors.New("invalid host " + strconv.Quote(host))
+	}
+
+	if whatwgEndsInNumber(asciiDomain) {
+		addr, ok := parseWhatwgIpv4(asciiDomain)
+		if !ok {
+			return "", errors.New("invalid ipv4 address " + strconv.Quote(host))
+		}
+
+		return addr.String(), nil
+	}
+
+	return asciiDomain, nil
+}
+
+// https://url.spec.whatwg.org/#ends-in-a-number-checker
+func whatwgEndsInNumber(domain string) bool {
+	parts := strings.Split(domain, ".")
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+
+	last := parts[len(parts)-1]
+	if last != "" && strings.Trim(last, "0123456789") == "" {
+		return true
+	}
+
+	_, ok := parseWhatwgIpv4Number(last)
+
+	return ok && last != ""
+}
+
+// https://url.spec.whatwg.org/#concept-ipv6-serializer
+func whatwgIpv6(addr netip.Addr) string {
+	bytes := addr.As16()
+	pieces := make([]uint16, 8)
+	for i := range pieces {
+		pieces[i] = uint16(bytes[2*i])<<8 | uint16(bytes[2*i+1])
+	}
+
+	compressStart, compressLen := -1, 1
+	for i := 0; i < len(pieces); {
+		if pieces[i] != 0 {
+			i++
+
+			continue
+		}
+
+		j := i
+		for j < len(pieces) && pieces[j] == 0 {
+			j++
+		}
+
+		if j-i > compressLen {
+			compressStart, compressLen = i, j-i
+		}
+
+		i = j
+	}
+
+	var serialized strings.Builder
+	for i := 0; i < len(pieces); i++ {
+		if i == compressStart {
+			if i == 0 {
+				serialized.WriteString("::")
+			} else {
+				serialized.WriteString(":")
+			}
+
+			i += compressLen - 1
+
+			continue
+		}
+
+		serialized.WriteString(strconv.FormatUint(uint64(pieces[i]), 16))
+		if i != len(pieces)-1 {
+			serialized.WriteString(":")
+		}
+	}
+
+	return serialized.String()
+}
+
+// whatwgPath percent-encodes the path and resolves its dot segments, see https://url.spec.whatwg.org/#path-state.
+func whatwgPath(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	segments := strings.Split(path[1:], "/")
+	resolved := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		switch strings.ToLower(segment) {
+		case ".", "%2e":
+			if last {
+				resolved = append(resolved, "")
+			}
+		case "..", ".%2e", "%2e.", "%2e%2e":
+			if len(resolved) > 0 {
+				resolved = resolved[:len(resolved)-1]
+			}
+
+			if last {
+				resolved = append(resolved, "")
+			}
+		default:
+			resolved = append(resolved, whatwgPercentEncode(segment, whatwgPathSet))
+		}
+	}
+
+	return "/" + strings.Join(resolved, "/")
+}
+
+// whatwgPercentEncode encodes C0 controls, non-ASCII bytes and the given set. Unlike browsers, it also encodes
+// percent signs which don't start a valid escape sequence, as url.Parse would refuse them.
+func whatwgPercentEncode(s, set string) string {
+	const hex = "0123456789ABCDEF"
+
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && (i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2])):
+			encoded.WriteString("%25")
+		case c < ' ' || c > '~' || strings.IndexByte(set, c) != -1:
+			encoded.WriteByte('%')
+			encoded.WriteByte(hex[c>>4])
+			encoded.WriteByte(hex[c&15])
+		default:
+			encoded.WriteByte(c)
+		}
+	}
+
+	return encoded.String()
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,178 @@
+package urlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhatwgComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawUrl    string
+		component string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "ref url",
+			component: "relativeUrl",
+			expected:  "/lorem/ipsum/dolor/sit.html?amet=elit&metus=lectus#at=nostra&unde=omnis",
+		},
+		{
+			name:      "scheme and host are lowercased",
+			rawUrl:    "HTTPS://WWW.Example.COM/Lorem",
+			component: "authority",
+			expected:  "www.example.com",
+		},
+		{
+			name:      "backslashes are slashes",
+			rawUrl:    "https:\\\\evil.example.com\\lorem\\ipsum",
+			component: "host",
+			expected:  "evil.example.com",
+		},
+		{
+			name:      "backslashes in path",
+			rawUrl:    "https://example.com\\lorem\\ipsum?a=\\b",
+			component: "relativeUrl",
+			expected:  "/lorem/ipsum?a=\\b",
+		},
+		{
+			name:      "missing slashes",
+			rawUrl:    "http:example.com/lorem",
+			component: "host",
+			expected:  "example.com",
+		},
+		{
+			name:      "tabs and newlines are removed",
+			rawUrl:    " \thttps://exa\nmple.c\tom/lo\rrem\n",
+			component: "hostPort",
+			expected:  "example.com",
+		},
+		{
+			name:      "default port is removed",
+			rawUrl:    "https://example.com:443/",
+			component: "port",
+		},
+		{
+			name:      "port leading zeros",
+			rawUrl:    "http://example.com:0080/",
+			component: "port",
+		},
+		{
+			name:      "dot segments are resolved",
+			rawUrl:    "http://example.com/lorem/./ipsum/../%2e%2E/dolor",
+			component: "path",
+			expected:  "/dolor",
+		},
+		{
+			name:      "empty path",
+			rawUrl:    "http://example.com",
+			component: "path",
+			expected:  "/",
+		},
+		{
+			name:      "ipv4 number host",
+			rawUrl:    "http://0x7f.1/",
+			component: "host",
+			expected:  "127.0.0.1",
+		},
+		{
+			name:      "ipv4-mapped ipv6 host",
+			rawUrl:    "http://[::FFFF:127.0.0.1]:8080/",
+			component: "hostPort",
+			expected:  "[::ffff:7f00:1]:8080",
+		},
+		{
+			name:      "percent-encoded host",
+			rawUrl:    "http://ex%61mple.com/",
+			component: "host",
+			expected:  "example.com",
+		},
+		{
+			name:      "internationalized host",
+			rawUrl:    "https://bücher.example/",
+			component: "host",
+			expected:  "xn--bcher-kva.example",
+		},
+		{
+			name:      "at sign in password",
+			rawUrl:    "http://user:p@ss@example.com/",
+			component: "auth",
+			expected:  "user:p%40ss",
+		},
+		{
+			name:      "invalid percent-encoding",
+			rawUrl:    "foo://example.com/%zz",
+			component: "path",
+			expected:  "/%zz",
+		},
+		{
+			name:      "non-special scheme keeps backslashes",
+			rawUrl:    "foo://example.com\\lorem",
+			component: "host",
+			expectErr: true,
+		},
+		{
+			name:      "non-special scheme without path",
+			rawUrl:    "foo://",
+			component: "host",
+			expected:  "",
+		},
+		{
+			name:      "opaque url",
+			rawUrl:    "mailto:user@example.com",
+			component: "scheme",
+			expected:  "mailto",
+		},
+		{
+			name:      "file url",
+			rawUrl:    "file://localhost/etc/hosts",
+			component: "relativeUrl",
+			expected:  "/etc/hosts",
+		},
+		{
+			name:      "relative url",
+			rawUrl:    "/lorem/ipsum.html",
+			component: "path",
+			expectErr: true,
+		},
+		{
+			name:      "invalid ipv4",
+			rawUrl:    "http://1.2.3.256/",
+			component: "host",
+			expectErr: true,
+		},
+		{
+			name:      "empty host",
+			rawUrl:    "https://",
+			component: "host",
+			expectErr: true,
+		},
+		{
+			name:      "invalid port",
+			rawUrl:    "http://example.com:65536/",
+			component: "port",
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			if test.rawUrl == "" {
+				test.rawUrl = refUrl
+			}
+
+			result, err := Parser{Whatwg: true}.Component(test.rawUrl, test.component)
+
+			if test.expectErr {
+				require.Error(err)
+			} else {
+				require.NoError(err)
+			}
+
+			require.Equal(test.expected, result)
+		})
+	}
+}